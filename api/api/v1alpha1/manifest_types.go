@@ -112,7 +112,7 @@ type ManifestSpec struct {
 	Sync Sync `json:"sync,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=Processing;Deleting;Ready;Error
+// +kubebuilder:validation:Enum=Processing;Deleting;Ready;Error;Warning
 type ManifestState string
 
 // Valid Helm States
@@ -128,6 +128,9 @@ const (
 
 	// ManifestStateDeleting signifies Manifest is being deleted
 	ManifestStateDeleting ManifestState = "Deleting"
+
+	// ManifestStateWarning signifies Manifest resources are installed, but degraded
+	ManifestStateWarning ManifestState = "Warning"
 )
 
 // ManifestStatus defines the observed state of Manifest
@@ -17,6 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -64,14 +67,23 @@ var _ webhook.Validator = &Manifest{}
 func (m *Manifest) ValidateCreate() error {
 	manifestlog.Info("validate create", "name", m.Name)
 
-	return m.validateInstalls()
+	return m.validate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (m *Manifest) ValidateUpdate(old runtime.Object) error {
 	manifestlog.Info("validate update", "name", m.Name)
 
-	return m.validateInstalls()
+	// finalizer removal and other metadata updates must not be blocked by specs,
+	// which were accepted before the validation was introduced
+	if !m.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	if oldManifest, ok := old.(*Manifest); ok && equality.Semantic.DeepEqual(oldManifest.Spec, m.Spec) {
+		return nil
+	}
+
+	return m.validate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -80,40 +92,100 @@ func (m *Manifest) ValidateDelete() error {
 	return nil
 }
 
-func (m *Manifest) validateInstalls() error {
+func (m *Manifest) validate() error {
+	fieldErrors := append(m.validateInstalls(), m.validateSync()...)
+
+	if len(fieldErrors) > 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: ManifestKind},
+			m.Name, fieldErrors)
+	}
+
+	return nil
+}
+
+func (m *Manifest) validateInstalls() field.ErrorList {
 	fieldErrors := make(field.ErrorList, 0)
+	installsPath := field.NewPath("spec").Child("installs")
 
 	codec, err := NewCodec()
 	if err != nil {
-		fieldErrors = append(fieldErrors,
-			field.Invalid(field.NewPath("spec").Child("installs"),
-				"validator initialize", err.Error()))
+		return append(fieldErrors, field.Invalid(installsPath, "validator initialize", err.Error()))
+	}
+
+	installNames := make(map[string]bool)
+	for i, install := range m.Spec.Installs {
+		installPath := installsPath.Index(i)
+		if install.Name == "" {
+			fieldErrors = append(fieldErrors, field.Required(installPath.Child("name"), "install name is required"))
+		} else if installNames[install.Name] {
+			fieldErrors = append(fieldErrors, field.Duplicate(installPath.Child("name"), install.Name))
+		}
+		installNames[install.Name] = true
+
+		sourcePath := installPath.Child("source")
+		specType, err := GetSpecType(install.Source.Raw)
+		if err != nil {
+			fieldErrors = append(fieldErrors, field.Invalid(sourcePath, string(install.Source.Raw), err.Error()))
+			continue
+		}
+
+		if err = codec.Validate(install.Source.Raw, specType); err != nil {
+			fieldErrors = append(fieldErrors, field.Invalid(sourcePath, string(install.Source.Raw), err.Error()))
+			continue
+		}
+
+		fieldErrors = append(fieldErrors, validateSourceFields(codec, install.Source.Raw, specType, sourcePath)...)
 	}
 
-	if len(fieldErrors) == 0 {
-		for _, install := range m.Spec.Installs {
-			specType, err := GetSpecType(install.Source.Raw)
-			if err != nil {
-				fieldErrors = append(fieldErrors,
-					field.Invalid(field.NewPath("spec").Child("installs"),
-						install.Source.Raw, err.Error()))
-				continue
-			}
-
-			err = codec.Validate(install.Source.Raw, specType)
-			if err != nil {
-				fieldErrors = append(fieldErrors,
-					field.Invalid(field.NewPath("spec").Child("installs"),
-						install.Source.Raw, err.Error()))
-			}
+	return fieldErrors
+}
+
+// validateSourceFields verifies the fields required to locate the chart of the given type are present.
+func validateSourceFields(codec *Codec, source []byte, specType RefTypeMetadata, sourcePath *field.Path,
+) field.ErrorList {
+	fieldErrors := make(field.ErrorList, 0)
+	required := make(map[string]string)
+
+	switch specType {
+	case HelmChartType:
+		var helmChartSpec HelmChartSpec
+		if err := codec.Decode(source, &helmChartSpec, specType); err != nil {
+			return append(fieldErrors, field.Invalid(sourcePath, string(source), err.Error()))
+		}
+		required["url"] = helmChartSpec.Url
+		required["chartName"] = helmChartSpec.ChartName
+	case OciRefType:
+		var imageSpec ImageSpec
+		if err := codec.Decode(source, &imageSpec, specType); err != nil {
+			return append(fieldErrors, field.Invalid(sourcePath, string(source), err.Error()))
 		}
+		required["repo"] = imageSpec.Repo
+		required["name"] = imageSpec.Name
+		required["ref"] = imageSpec.Ref
 	}
 
-	if len(fieldErrors) > 0 {
-		return apierrors.NewInvalid(
-			schema.GroupKind{Group: GroupVersion.Group, Kind: ManifestKind},
-			m.Name, fieldErrors)
+	for _, name := range []string{"url", "chartName", "repo", "name", "ref"} {
+		if value, ok := required[name]; ok && value == "" {
+			fieldErrors = append(fieldErrors, field.Required(sourcePath.Child(name),
+				fmt.Sprintf("%s is required for source type %s", name, specType)))
+		}
 	}
 
-	return nil
+	return fieldErrors
+}
+
+func (m *Manifest) validateSync() field.ErrorList {
+	fieldErrors := make(field.ErrorList, 0)
+	syncPath := field.NewPath("spec").Child("sync")
+	sync := m.Spec.Sync
+
+	switch sync.Strategy {
+	case "", SyncStrategyRemoteSecret, SyncStrategyLocalSecret:
+	default:
+		fieldErrors = append(fieldErrors, field.NotSupported(syncPath.Child("strategy"), sync.Strategy,
+			[]string{string(SyncStrategyRemoteSecret), string(SyncStrategyLocalSecret)}))
+	}
+
+	return fieldErrors
 }
@@ -0,0 +1,120 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	validHelmSource = `{"type":"helm-chart","url":"https://charts.example.com","chartName":"nginx"}`
+	validOciSource  = `{"type":"oci-ref","repo":"registry.example.com","name":"nginx","ref":"sha256:123"}`
+)
+
+func manifestWithSpec(spec ManifestSpec) *Manifest {
+	return &Manifest{ObjectMeta: metav1.ObjectMeta{Name: "manifest-sample"}, Spec: spec}
+}
+
+func installWithSource(name string, source string) InstallInfo {
+	return InstallInfo{Name: name, Source: runtime.RawExtension{Raw: []byte(source)}}
+}
+
+var _ = Describe("Manifest validation", func() {
+	DescribeTable("valid specs",
+		func(spec ManifestSpec) {
+			Expect(manifestWithSpec(spec).ValidateCreate()).To(Succeed())
+		},
+		Entry("without installs", ManifestSpec{}),
+		Entry("with helm chart and oci image installs", ManifestSpec{
+			Installs: []InstallInfo{installWithSource("helm", validHelmSource), installWithSource("oci", validOciSource)},
+		}),
+		Entry("with enabled sync", ManifestSpec{
+			Sync: Sync{Enabled: true, Strategy: SyncStrategyLocalSecret, Namespace: "remote"},
+		}),
+		Entry("with disabled sync keeping its settings", ManifestSpec{
+			Sync: Sync{Enabled: false, Strategy: SyncStrategyRemoteSecret, Namespace: "remote"},
+		}),
+	)
+
+	DescribeTable("invalid specs",
+		func(spec ManifestSpec, field string) {
+			err := manifestWithSpec(spec).ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			statusErr, ok := err.(*apierrors.StatusError)
+			Expect(ok).To(BeTrue())
+			Expect(statusErr.ErrStatus.Details.Causes).To(ContainElement(
+				WithTransform(func(cause metav1.StatusCause) string { return cause.Field }, Equal(field))))
+		},
+		Entry("with unsupported source type", ManifestSpec{
+			Installs: []InstallInfo{installWithSource("unknown", `{"type":"kustomize"}`)},
+		}, "spec.installs[0].source"),
+		Entry("with missing install name", ManifestSpec{
+			Installs: []InstallInfo{installWithSource("", validHelmSource)},
+		}, "spec.installs[0].name"),
+		Entry("with duplicate install names", ManifestSpec{
+			Installs: []InstallInfo{installWithSource("helm", validHelmSource), installWithSource("helm", validOciSource)},
+		}, "spec.installs[1].name"),
+		Entry("with helm chart missing url", ManifestSpec{
+			Installs: []InstallInfo{installWithSource("helm", `{"type":"helm-chart","chartName":"nginx"}`)},
+		}, "spec.installs[0].source.url"),
+		Entry("with helm chart missing chart name", ManifestSpec{
+			Installs: []InstallInfo{installWithSource("helm", `{"type":"helm-chart","url":"https://charts.example.com"}`)},
+		}, "spec.installs[0].source.chartName"),
+		Entry("with oci image missing ref", ManifestSpec{
+			Installs: []InstallInfo{installWithSource("oci", `{"type":"oci-ref","repo":"registry.example.com","name":"nginx"}`)},
+		}, "spec.installs[0].source.ref"),
+		Entry("with unsupported sync strategy", ManifestSpec{
+			Sync: Sync{Enabled: true, Strategy: "unknown"},
+		}, "spec.sync.strategy"),
+	)
+
+	When("an existing Manifest with an invalid spec is updated", func() {
+		invalidSpec := ManifestSpec{
+			Installs: []InstallInfo{installWithSource("helm", validHelmSource), installWithSource("helm", validHelmSource)},
+		}
+
+		It("should allow metadata updates", func() {
+			oldManifest := manifestWithSpec(invalidSpec)
+			updatedManifest := oldManifest.DeepCopy()
+			updatedManifest.SetAnnotations(map[string]string{"component.kyma-project.io/failure-count": "1"})
+			Expect(updatedManifest.ValidateUpdate(oldManifest)).To(Succeed())
+		})
+
+		It("should allow finalizer removal during deletion", func() {
+			oldManifest := manifestWithSpec(invalidSpec)
+			oldManifest.SetFinalizers([]string{"component.kyma-project.io/manifest"})
+			deletionTimestamp := metav1.Now()
+			oldManifest.SetDeletionTimestamp(&deletionTimestamp)
+			updatedManifest := oldManifest.DeepCopy()
+			updatedManifest.SetFinalizers(nil)
+			Expect(updatedManifest.ValidateUpdate(oldManifest)).To(Succeed())
+		})
+
+		It("should reject spec changes that keep it invalid", func() {
+			oldManifest := manifestWithSpec(invalidSpec)
+			updatedManifest := oldManifest.DeepCopy()
+			updatedManifest.Spec.Installs = append(updatedManifest.Spec.Installs,
+				installWithSource("oci", validOciSource))
+			Expect(apierrors.IsInvalid(updatedManifest.ValidateUpdate(oldManifest))).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/manifest-operator/api/api/v1alpha1"
+	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
+)
+
+// failureBackoff returns the requeue interval for the given number of consecutive failures.
+// It doubles RequeueIntervals.Failure with every failure, capped at RequeueIntervals.FailureMax.
+// Without FailureMax the failure interval stays constant.
+func failureBackoff(intervals RequeueIntervals, failures int) time.Duration {
+	backoff := intervals.Failure
+	if intervals.FailureMax <= backoff {
+		return backoff
+	}
+	for i := 1; i < failures; i++ {
+		backoff *= 2
+		if backoff >= intervals.FailureMax {
+			return intervals.FailureMax
+		}
+	}
+	return backoff
+}
+
+// remainingFailureBackoff returns the time left until a Manifest in error state should be retried,
+// based on the failure annotations, which survive controller restarts.
+func remainingFailureBackoff(manifestObj *v1alpha1.Manifest, intervals RequeueIntervals, now time.Time,
+) time.Duration {
+	annotations := manifestObj.GetAnnotations()
+	failures, err := strconv.Atoi(annotations[labels.FailureCount])
+	if err != nil || failures < 1 {
+		return 0
+	}
+	lastAttempt, err := time.Parse(time.RFC3339, annotations[labels.LastFailedAttempt])
+	if err != nil {
+		return 0
+	}
+	return lastAttempt.Add(failureBackoff(intervals, failures)).Sub(now)
+}
+
+// recordFailure increases the consecutive failure count and sets the last failed attempt on the Manifest.
+func recordFailure(manifestObj *v1alpha1.Manifest, now time.Time) {
+	annotations := manifestObj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	failures, err := strconv.Atoi(annotations[labels.FailureCount])
+	if err != nil {
+		failures = 0
+	}
+	annotations[labels.FailureCount] = strconv.Itoa(failures + 1)
+	annotations[labels.LastFailedAttempt] = now.UTC().Format(time.RFC3339)
+	manifestObj.SetAnnotations(annotations)
+}
+
+// resetFailures removes the failure annotations and returns true, if any of them was present.
+func resetFailures(manifestObj *v1alpha1.Manifest) bool {
+	annotations := manifestObj.GetAnnotations()
+	_, hasCount := annotations[labels.FailureCount]
+	_, hasAttempt := annotations[labels.LastFailedAttempt]
+	if !hasCount && !hasAttempt {
+		return false
+	}
+	delete(annotations, labels.FailureCount)
+	delete(annotations, labels.LastFailedAttempt)
+	manifestObj.SetAnnotations(annotations)
+	return true
+}
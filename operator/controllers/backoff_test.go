@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/kyma-project/manifest-operator/api/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Failure backoff", func() {
+	intervals := RequeueIntervals{Failure: 10 * time.Second, FailureMax: time.Minute}
+
+	When("failures are consecutive", func() {
+		It("should double the failure interval up to the maximum", func() {
+			Expect(failureBackoff(intervals, 1)).To(Equal(10 * time.Second))
+			Expect(failureBackoff(intervals, 2)).To(Equal(20 * time.Second))
+			Expect(failureBackoff(intervals, 3)).To(Equal(40 * time.Second))
+			Expect(failureBackoff(intervals, 4)).To(Equal(time.Minute))
+			Expect(failureBackoff(intervals, 100)).To(Equal(time.Minute))
+		})
+	})
+
+	When("failures are recorded on a Manifest", func() {
+		It("should back off from the last failed attempt until reset", func() {
+			manifestObj := &v1alpha1.Manifest{}
+			now := time.Now().Truncate(time.Second)
+			Expect(remainingFailureBackoff(manifestObj, intervals, now)).To(BeZero())
+
+			recordFailure(manifestObj, now)
+			recordFailure(manifestObj, now)
+			Expect(remainingFailureBackoff(manifestObj, intervals, now.Add(5*time.Second))).
+				To(Equal(15 * time.Second))
+
+			Expect(resetFailures(manifestObj)).To(BeTrue())
+			Expect(remainingFailureBackoff(manifestObj, intervals, now)).To(BeZero())
+			Expect(resetFailures(manifestObj)).To(BeFalse())
+		})
+	})
+})
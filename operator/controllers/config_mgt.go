@@ -9,6 +9,8 @@ import (
 	"github.com/kyma-project/manifest-operator/operator/pkg/descriptor"
 	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
 	"github.com/kyma-project/manifest-operator/operator/pkg/manifest"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/strvals"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,10 +18,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/yaml"
+	"strings"
 )
 
 func prepareDeployInfos(ctx context.Context, manifestObj *v1alpha1.Manifest, defaultClient client.Client,
-	verifyInstallation bool, customStateCheck bool, codec *v1alpha1.Codec,
+	verifyInstallation bool, customStateCheck bool, codec *v1alpha1.Codec, postRenderer postrender.PostRenderer,
 ) ([]manifest.DeployInfo, error) {
 	deployInfos := make([]manifest.DeployInfo, 0)
 	namespacedName := client.ObjectKeyFromObject(manifestObj)
@@ -80,6 +83,7 @@ func prepareDeployInfos(ctx context.Context, manifestObj *v1alpha1.Manifest, def
 			RestConfig:     restConfig,
 			CheckFn:        customResCheck.CheckProcessingFn,
 			ReadyCheck:     verifyInstallation,
+			PostRenderer:   postRenderer,
 		}
 		if !customStateCheck {
 			deployInfo.CheckFn = nil
@@ -105,6 +109,14 @@ func getChartInfoForInstall(install v1alpha1.InstallInfo, codec *v1alpha1.Codec,
 			return nil, err
 		}
 
+		// charts from OCI registries are referenced directly instead of through a named repo
+		if registry.IsOCI(helmChartSpec.Url) {
+			return &manifest.ChartInfo{
+				ChartName: fmt.Sprintf("%s/%s", strings.TrimSuffix(helmChartSpec.Url, "/"), helmChartSpec.ChartName),
+				Url:       helmChartSpec.Url,
+			}, nil
+		}
+
 		return &manifest.ChartInfo{
 			ChartName: fmt.Sprintf("%s/%s", install.Name, helmChartSpec.ChartName),
 			RepoName:  install.Name,
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"github.com/kyma-project/manifest-operator/api/api/v1alpha1"
+	"github.com/kyma-project/manifest-operator/operator/pkg/manifest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Chart info of installs", func() {
+	DescribeTable("resolving helm chart sources",
+		func(source string, expected manifest.ChartInfo) {
+			codec, err := v1alpha1.NewCodec()
+			Expect(err).NotTo(HaveOccurred())
+			install := v1alpha1.InstallInfo{Name: "nginx-stable", Source: runtime.RawExtension{Raw: []byte(source)}}
+
+			chartInfo, err := getChartInfoForInstall(install, codec, manifestInState(v1alpha1.ManifestStateProcessing))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*chartInfo).To(Equal(expected))
+			Expect(chartInfo.FromRegistry()).To(Equal(expected.RepoName == ""))
+		},
+		Entry("oci url",
+			`{"type": "helm-chart", "url": "oci://registry.example.com/charts/", "chartName": "nginx-ingress"}`,
+			manifest.ChartInfo{
+				ChartName: "oci://registry.example.com/charts/nginx-ingress",
+				Url:       "oci://registry.example.com/charts/",
+			}),
+		Entry("https url",
+			`{"type": "helm-chart", "url": "https://helm.nginx.com/stable", "chartName": "nginx-ingress"}`,
+			manifest.ChartInfo{
+				ChartName: "nginx-stable/nginx-ingress",
+				RepoName:  "nginx-stable",
+				Url:       "https://helm.nginx.com/stable",
+			}),
+	)
+})
@@ -19,20 +19,29 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/kyma-project/manifest-operator/api/api/v1alpha1"
 	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
 	"github.com/kyma-project/manifest-operator/operator/pkg/manifest"
+	"github.com/kyma-project/manifest-operator/operator/pkg/metrics"
+	manifestRest "github.com/kyma-project/manifest-operator/operator/pkg/rest"
+	"github.com/kyma-project/manifest-operator/operator/pkg/util"
 	"golang.org/x/time/rate"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/postrender"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -45,9 +54,11 @@ import (
 )
 
 type RequeueIntervals struct {
-	Success time.Duration
-	Failure time.Duration
-	Waiting time.Duration
+	Success    time.Duration
+	Failure    time.Duration
+	FailureMax time.Duration
+	Waiting    time.Duration
+	Warning    time.Duration
 }
 
 type ManifestDeploy struct {
@@ -69,10 +80,20 @@ type ManifestReconciler struct {
 	VerifyInstallation      bool
 	CustomStateCheck        bool
 	Codec                   *v1alpha1.Codec
+	PostRenderer            postrender.PostRenderer
+	Recorder                record.EventRecorder
 }
 
 const configReadError = "reading install config resulted in an error"
 
+// event reasons recorded on the Manifest.
+const (
+	ResourcesRetainedReason      = "ResourcesRetained"
+	ResourcesDriftedReason       = "ResourcesDrifted"
+	ReadyCheckSkippedReason      = "ReadyCheckSkipped"
+	ChartPreparationFailedReason = "ChartPreparationFailed"
+)
+
 //+kubebuilder:rbac:groups=component.kyma-project.io,resources=manifests,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=component.kyma-project.io,resources=manifests/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=component.kyma-project.io,resources=manifests/finalizers,verbs=update
@@ -95,6 +116,12 @@ func (r *ManifestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 	manifestObj = *manifestObj.DeepCopy()
+	// record the state this reconciliation ended in, the status is updated in place by the state handlers
+	defer func() {
+		if manifestObj.Status.State != "" {
+			metrics.RecordReconcileState(string(manifestObj.Status.State))
+		}
+	}()
 
 	randomizeDuration := func(input time.Duration) time.Duration {
 		millis := int(input / time.Millisecond)
@@ -132,11 +159,20 @@ func (r *ManifestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	case v1alpha1.ManifestStateDeleting:
 		return ctrl.Result{}, r.HandleDeletingState(ctx, &logger, &manifestObj)
 	case v1alpha1.ManifestStateError:
+		// back off from retrying, unless the spec changed in the meantime
+		if manifestObj.Generation == manifestObj.Status.ObservedGeneration {
+			if remaining := remainingFailureBackoff(&manifestObj, r.RequeueIntervals, time.Now()); remaining > 0 {
+				return ctrl.Result{RequeueAfter: remaining}, nil
+			}
+		}
 		return ctrl.Result{RequeueAfter: randomizeDuration(r.RequeueIntervals.Failure)},
 			r.HandleErrorState(ctx, &manifestObj)
 	case v1alpha1.ManifestStateReady:
 		return ctrl.Result{RequeueAfter: randomizeDuration(r.RequeueIntervals.Success)},
 			r.HandleReadyState(ctx, &logger, &manifestObj)
+	case v1alpha1.ManifestStateWarning:
+		return ctrl.Result{RequeueAfter: randomizeDuration(r.RequeueIntervals.Warning)},
+			r.HandleReadyState(ctx, &logger, &manifestObj)
 	}
 
 	// should not be reconciled again
@@ -161,18 +197,19 @@ func (r *ManifestReconciler) HandleDeletingState(ctx context.Context, logger *lo
 func (r *ManifestReconciler) jobAllocator(ctx context.Context, logger *logr.Logger, manifestObj *v1alpha1.Manifest, mode manifest.Mode,
 ) error {
 	namespacedName := client.ObjectKeyFromObject(manifestObj)
-	responseChan := make(manifest.RequestErrChan)
 
-	chartCount := len(manifestObj.Spec.Installs)
+	deployInfos, err := prepareDeployInfos(ctx, manifestObj, r.Client, r.VerifyInstallation, r.CustomStateCheck, r.Codec,
+		r.PostRenderer)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("error while preparing charts of manifest %s", namespacedName))
+		return r.updateManifestPreparationError(ctx, manifestObj, err)
+	}
 
-	// response handler in a separate go-routine
-	go r.ResponseHandlerFunc(ctx, logger, chartCount, responseChan, namespacedName)
+	// response handler in a separate go-routine, only started once all deploy requests can be sent
+	responseChan := make(manifest.RequestErrChan)
+	go r.ResponseHandlerFunc(ctx, logger, len(deployInfos), responseChan, namespacedName)
 
 	// send deploy requests
-	deployInfos, err := prepareDeployInfos(ctx, manifestObj, r.Client, r.VerifyInstallation, r.CustomStateCheck, r.Codec)
-	if err != nil {
-		return err
-	}
 
 	for _, deployInfo := range deployInfos {
 		r.DeployChan <- ManifestDeploy{
@@ -201,21 +238,38 @@ func (r *ManifestReconciler) HandleReadyState(ctx context.Context, logger *logr.
 	logger.Info("checking consistent state for " + namespacedName.String())
 
 	// send deploy requests
-	deployInfos, err := prepareDeployInfos(ctx, manifestObj, r.Client, r.VerifyInstallation, r.CustomStateCheck, r.Codec)
+	deployInfos, err := prepareDeployInfos(ctx, manifestObj, r.Client, r.VerifyInstallation, r.CustomStateCheck, r.Codec,
+		r.PostRenderer)
 	if err != nil {
-		return err
+		logger.Error(err, fmt.Sprintf("error while preparing charts of manifest %s", namespacedName))
+		return r.updateManifestPreparationError(ctx, manifestObj, err)
 	}
 
+	degradedCharts := make([]string, 0)
 	for _, deployInfo := range deployInfos {
 		args := prepareArgs(&deployInfo)
-		manifestOperations, err := manifest.NewOperations(logger, deployInfo.RestConfig, deployInfo.ReleaseName,
+		restConfig := manifestRest.WithContextCancellation(deployInfo.Ctx, deployInfo.RestConfig)
+		manifestOperations, err := manifest.NewOperations(logger, restConfig, deployInfo.ReleaseName,
 			cli.New(), args)
 		if err != nil {
 			logger.Error(err, fmt.Sprintf("error while creating library operations for manifest %s", namespacedName))
 			return r.updateManifestStatus(ctx, manifestObj, v1alpha1.ManifestStateError, err.Error())
 		}
 
-		if ready, err := manifestOperations.VerifyResources(deployInfo); !ready {
+		ready, err := manifestOperations.VerifyResources(deployInfo)
+		if errors.Is(err, manifest.ErrResourcesDrifted) {
+			// resources changed out of band are re-applied in the processing state
+			r.Recorder.Eventf(manifestObj, v1.EventTypeWarning, ResourcesDriftedReason, "chart %s: %s",
+				deployInfo.ChartName, err.Error())
+			return r.updateManifestStatus(ctx, manifestObj, v1alpha1.ManifestStateProcessing,
+				fmt.Sprintf("chart %s: %s", deployInfo.ChartName, err.Error()))
+		}
+		if errors.Is(err, manifest.ErrResourcesDegraded) {
+			// degraded resources are not fatal, continue checking the remaining charts
+			degradedCharts = append(degradedCharts, deployInfo.ChartName)
+			continue
+		}
+		if !ready {
 			return r.updateManifestStatus(ctx, manifestObj, v1alpha1.ManifestStateProcessing,
 				"resources not ready")
 		} else if err != nil {
@@ -223,6 +277,26 @@ func (r *ManifestReconciler) HandleReadyState(ctx context.Context, logger *logr.
 			return r.updateManifestStatus(ctx, manifestObj, v1alpha1.ManifestStateError, err.Error())
 		}
 	}
+
+	return r.updateDegradedState(ctx, logger, manifestObj, degradedCharts)
+}
+
+// updateDegradedState moves a consistent Manifest to Warning if any of its charts is degraded,
+// and back to Ready once all of them recovered.
+func (r *ManifestReconciler) updateDegradedState(ctx context.Context, logger *logr.Logger,
+	manifestObj *v1alpha1.Manifest, degradedCharts []string,
+) error {
+	if len(degradedCharts) > 0 {
+		logger.Info("degraded resources for " + client.ObjectKeyFromObject(manifestObj).String())
+		return r.updateManifestStatus(ctx, manifestObj, v1alpha1.ManifestStateWarning,
+			fmt.Sprintf("%s for charts %s", manifest.ErrResourcesDegraded.Error(), strings.Join(degradedCharts, ", ")))
+	}
+
+	if manifestObj.Status.State != v1alpha1.ManifestStateReady {
+		// recovered from degradation
+		return r.updateManifestStatus(ctx, manifestObj, v1alpha1.ManifestStateReady,
+			fmt.Sprintf("%s in %s state", v1alpha1.ManifestKind, v1alpha1.ManifestStateReady))
+	}
 	return nil
 }
 
@@ -232,19 +306,76 @@ func (r *ManifestReconciler) updateManifest(ctx context.Context, manifestObj *v1
 
 func (r *ManifestReconciler) updateManifestStatus(ctx context.Context, manifestObj *v1alpha1.Manifest, state v1alpha1.ManifestState, message string,
 ) error {
+	previousStatus := manifestObj.Status.DeepCopy()
+	setManifestState(manifestObj, state, message)
+	if err := r.writeManifestFailures(ctx, manifestObj); err != nil {
+		return err
+	}
+	return r.writeManifestStatus(ctx, manifestObj, previousStatus)
+}
+
+// updateManifestPreparationError moves the Manifest to the error state, if its charts cannot be prepared,
+// and reports the error in a Warning event.
+func (r *ManifestReconciler) updateManifestPreparationError(ctx context.Context, manifestObj *v1alpha1.Manifest,
+	err error,
+) error {
+	r.Recorder.Event(manifestObj, v1.EventTypeWarning, ChartPreparationFailedReason, err.Error())
+	return r.updateManifestStatus(ctx, manifestObj, v1alpha1.ManifestStateError, err.Error())
+}
+
+// setManifestState sets the state and the matching Manifest ready condition, without writing the status.
+func setManifestState(manifestObj *v1alpha1.Manifest, state v1alpha1.ManifestState, message string) {
 	manifestObj.Status.State = state
 	switch state {
 	case v1alpha1.ManifestStateReady:
 		addReadyConditionForObjects(manifestObj, []v1alpha1.InstallItem{{ChartName: v1alpha1.ManifestKind}},
 			v1alpha1.ConditionStatusTrue, message)
-	case "":
+	case "", v1alpha1.ManifestStateWarning:
+		// degraded resources are installed, so readiness is unknown rather than failed as in the error state
 		addReadyConditionForObjects(manifestObj, []v1alpha1.InstallItem{{ChartName: v1alpha1.ManifestKind}},
 			v1alpha1.ConditionStatusUnknown, message)
 	default:
 		addReadyConditionForObjects(manifestObj, []v1alpha1.InstallItem{{ChartName: v1alpha1.ManifestKind}},
 			v1alpha1.ConditionStatusFalse, message)
 	}
-	return r.Status().Update(ctx, manifestObj.SetObservedGeneration())
+}
+
+// writeManifestStatus updates the Manifest status, unless it is unchanged compared to previousStatus.
+// Skipping no-op writes avoids resource version conflicts with concurrent reconciles.
+func (r *ManifestReconciler) writeManifestStatus(ctx context.Context, manifestObj *v1alpha1.Manifest,
+	previousStatus *v1alpha1.ManifestStatus,
+) error {
+	manifestObj.SetObservedGeneration()
+	if manifestStatusChanged(previousStatus, &manifestObj.Status) {
+		if err := r.Status().Update(ctx, manifestObj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeManifestFailures tracks consecutive failures for the error state backoff and resets them once the Manifest is ready.
+// The failure annotations are written before the status, so that the error state always finds its backoff.
+// The status of manifestObj is kept, although the update returns the stored status.
+func (r *ManifestReconciler) writeManifestFailures(ctx context.Context, manifestObj *v1alpha1.Manifest) error {
+	failuresChanged := false
+	switch manifestObj.Status.State {
+	case v1alpha1.ManifestStateError:
+		recordFailure(manifestObj, time.Now())
+		failuresChanged = true
+	case v1alpha1.ManifestStateReady:
+		failuresChanged = resetFailures(manifestObj)
+	}
+	if !failuresChanged {
+		return nil
+	}
+
+	status := manifestObj.Status.DeepCopy()
+	if err := r.updateManifest(ctx, manifestObj); err != nil {
+		return err
+	}
+	manifestObj.Status = *status
+	return nil
 }
 
 func (r *ManifestReconciler) HandleCharts(deployInfo manifest.DeployInfo, mode manifest.Mode, logger *logr.Logger,
@@ -255,15 +386,18 @@ func (r *ManifestReconciler) HandleCharts(deployInfo manifest.DeployInfo, mode m
 	create := mode == manifest.CreateMode
 
 	var ready bool
+	var keptResources, skippedResources kube.ResourceList
 	// TODO: implement better settings handling
-	manifestOperations, err := manifest.NewOperations(logger, deployInfo.RestConfig, deployInfo.ReleaseName,
+	// requests to the target cluster are aborted, once the operation is cancelled
+	restConfig := manifestRest.WithContextCancellation(deployInfo.Ctx, deployInfo.RestConfig)
+	manifestOperations, err := manifest.NewOperations(logger, restConfig, deployInfo.ReleaseName,
 		cli.New(), args)
 
 	if err == nil {
 		if create {
-			ready, err = manifestOperations.Install(deployInfo)
+			ready, skippedResources, err = manifestOperations.Install(deployInfo)
 		} else {
-			ready, err = manifestOperations.Uninstall(deployInfo)
+			ready, keptResources, err = manifestOperations.Uninstall(deployInfo)
 		}
 	}
 
@@ -274,6 +408,8 @@ func (r *ManifestReconciler) HandleCharts(deployInfo manifest.DeployInfo, mode m
 		ChartName:         deployInfo.ChartName,
 		ClientConfig:      deployInfo.ClientConfig,
 		Overrides:         deployInfo.Overrides,
+		KeptResources:     keptResources,
+		SkippedResources:  skippedResources,
 	}
 }
 
@@ -284,6 +420,7 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 	errorState := false
 	processing := false
 	responses := make([]*manifest.RequestError, 0)
+	errs := make([]error, 0)
 
 	for a := 1; a <= chartCount; a++ {
 		select {
@@ -296,6 +433,7 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 			if response.Err != nil {
 				logger.Error(fmt.Errorf("chart installation failure for %s!!! : %w",
 					response.ResNamespacedName.String(), response.Err), "")
+				errs = append(errs, fmt.Errorf("chart %s: %w", response.ChartName, response.Err))
 				errorState = true
 			} else if !response.Ready {
 				logger.Info(fmt.Sprintf("chart checks still processing %s!!!",
@@ -310,6 +448,8 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 		logger.Error(err, "error while locating", "resource", namespacedName)
 		return
 	}
+	// all chart conditions and the end state are written with a single status update
+	previousStatus := latestManifestObj.Status.DeepCopy()
 
 	for _, response := range responses {
 		status := v1alpha1.ConditionStatusTrue
@@ -317,7 +457,7 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 
 		if response.Err != nil {
 			status = v1alpha1.ConditionStatusFalse
-			message = "installation error"
+			message = fmt.Sprintf("installation error for chart %s: %s", response.ChartName, response.Err.Error())
 		} else if !response.Ready {
 			status = v1alpha1.ConditionStatusUnknown
 			message = "installation processing"
@@ -340,6 +480,19 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 			Overrides:    string(overrideBytes),
 			ChartName:    response.ChartName,
 		}}, status, message)
+
+		if response.Err == nil && response.Ready && len(response.SkippedResources) > 0 {
+			// skipped resources might not be ready, although the chart is
+			r.Recorder.Eventf(latestManifestObj, v1.EventTypeNormal, ReadyCheckSkippedReason,
+				"resources of chart %s skipped in the ready check: %s", response.ChartName,
+				util.DescribeResources(response.SkippedResources))
+		}
+		if len(response.KeptResources) > 0 {
+			// retained resources outlive the Manifest and have to be cleaned up manually
+			r.Recorder.Eventf(latestManifestObj, v1.EventTypeNormal, ResourcesRetainedReason,
+				"resources of chart %s retained on deletion: %s", response.ChartName,
+				util.DescribeResources(response.KeptResources))
+		}
 	}
 
 	// handle deletion if no previous error occurred
@@ -347,6 +500,7 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 		if latestManifestObj.Spec.Sync.Enabled {
 			// remove finalizer on remote resource
 			if _, err := r.SyncRemoteResource(ctx, latestManifestObj, true); err != nil {
+				errs = append(errs, fmt.Errorf("remote sync: %w", err))
 				errorState = true
 				logger.Error(err, "unexpected error while syncing remote ",
 					"resource", namespacedName)
@@ -360,6 +514,7 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 				// finalizer removal failure
 				logger.Error(err, "unexpected error while removing finalizer from",
 					"resource", namespacedName)
+				errs = append(errs, fmt.Errorf("finalizer removal: %w", err))
 				errorState = true
 			} else {
 				// finalizer successfully removed
@@ -380,12 +535,21 @@ func (r *ManifestReconciler) ResponseHandlerFunc(ctx context.Context, logger *lo
 		}
 	}
 
+	message := fmt.Sprintf("%s in %s state", v1alpha1.ManifestKind, endState)
+	if len(errs) > 0 {
+		// aggregate all failures so each failing chart can be diagnosed from the status
+		message = fmt.Sprintf("%s: %s", message, utilerrors.NewAggregate(errs).Error())
+	}
+
 	// update status for non-deletion scenarios
-	if err := r.updateManifestStatus(ctx, latestManifestObj, endState,
-		fmt.Sprintf("%s in %s state", v1alpha1.ManifestKind, endState)); err != nil {
+	setManifestState(latestManifestObj, endState, message)
+	if err := r.writeManifestFailures(ctx, latestManifestObj); err != nil {
+		logger.Error(err, "error updating failure backoff", "resource", namespacedName)
+		return
+	}
+	if err := r.writeManifestStatus(ctx, latestManifestObj, previousStatus); err != nil {
 		logger.Error(err, "error updating status", "resource", namespacedName)
 	}
-	return
 }
 
 func (r *ManifestReconciler) SyncRemoteResource(ctx context.Context, manifestObj *v1alpha1.Manifest, removeFinalizer bool,
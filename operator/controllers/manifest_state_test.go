@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/manifest-operator/api/api/v1alpha1"
+	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
+	"github.com/kyma-project/manifest-operator/operator/pkg/manifest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeReconciler(ctx context.Context, manifestObj *v1alpha1.Manifest) *ManifestReconciler {
+	scheme := runtime.NewScheme()
+	Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(manifestObj).Build()
+	Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(manifestObj), manifestObj)).To(Succeed())
+	return &ManifestReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10)}
+}
+
+func manifestInState(state v1alpha1.ManifestState) *v1alpha1.Manifest {
+	return &v1alpha1.Manifest{
+		ObjectMeta: metav1.ObjectMeta{Name: "manifest", Namespace: metav1.NamespaceDefault},
+		Status:     v1alpha1.ManifestStatus{State: state},
+	}
+}
+
+func expectState(ctx context.Context, r *ManifestReconciler, manifestObj *v1alpha1.Manifest,
+	state v1alpha1.ManifestState, conditionStatus v1alpha1.ManifestConditionStatus,
+) *v1alpha1.ManifestCondition {
+	latest := &v1alpha1.Manifest{}
+	Expect(r.Get(ctx, client.ObjectKeyFromObject(manifestObj), latest)).To(Succeed())
+	Expect(latest.Status.State).To(Equal(state))
+	condition, exists := getReadyConditionForComponent(latest, v1alpha1.ManifestKind)
+	Expect(exists).To(BeTrue())
+	Expect(condition.Status).To(Equal(conditionStatus))
+	return condition
+}
+
+var _ = Describe("Manifest ready condition", func() {
+	DescribeTable("setting the state",
+		func(state v1alpha1.ManifestState, conditionStatus v1alpha1.ManifestConditionStatus) {
+			manifestObj := manifestInState("")
+			setManifestState(manifestObj, state, "message")
+			condition, exists := getReadyConditionForComponent(manifestObj, v1alpha1.ManifestKind)
+			Expect(exists).To(BeTrue())
+			Expect(condition.Status).To(Equal(conditionStatus))
+		},
+		Entry("ready", v1alpha1.ManifestStateReady, v1alpha1.ConditionStatusTrue),
+		Entry("warning", v1alpha1.ManifestStateWarning, v1alpha1.ConditionStatusUnknown),
+		Entry("error", v1alpha1.ManifestStateError, v1alpha1.ConditionStatusFalse),
+		Entry("processing", v1alpha1.ManifestStateProcessing, v1alpha1.ConditionStatusFalse),
+	)
+})
+
+var _ = Describe("Manifest degradation", func() {
+	logger := logr.Discard()
+	ctx := context.Background()
+
+	newReconciler := func(state v1alpha1.ManifestState) (*ManifestReconciler, *v1alpha1.Manifest) {
+		manifestObj := manifestInState(state)
+		return newFakeReconciler(ctx, manifestObj), manifestObj
+	}
+
+	When("a ready Manifest has degraded charts", func() {
+		It("should move to the warning state", func() {
+			r, manifestObj := newReconciler(v1alpha1.ManifestStateReady)
+			Expect(r.updateDegradedState(ctx, &logger, manifestObj, []string{"nginx"})).To(Succeed())
+			condition := expectState(ctx, r, manifestObj, v1alpha1.ManifestStateWarning,
+				v1alpha1.ConditionStatusUnknown)
+			Expect(condition.Message).To(ContainSubstring(manifest.ErrResourcesDegraded.Error()))
+			Expect(condition.Message).To(ContainSubstring("nginx"))
+		})
+	})
+
+	When("a Manifest in warning state recovers", func() {
+		It("should move back to the ready state", func() {
+			r, manifestObj := newReconciler(v1alpha1.ManifestStateWarning)
+			Expect(r.updateDegradedState(ctx, &logger, manifestObj, []string{"nginx"})).To(Succeed())
+			expectState(ctx, r, manifestObj, v1alpha1.ManifestStateWarning, v1alpha1.ConditionStatusUnknown)
+
+			Expect(r.updateDegradedState(ctx, &logger, manifestObj, nil)).To(Succeed())
+			expectState(ctx, r, manifestObj, v1alpha1.ManifestStateReady, v1alpha1.ConditionStatusTrue)
+		})
+	})
+
+	When("a ready Manifest has no degraded charts", func() {
+		It("should stay ready without a status update", func() {
+			r, manifestObj := newReconciler(v1alpha1.ManifestStateReady)
+			resourceVersion := manifestObj.ResourceVersion
+			Expect(r.updateDegradedState(ctx, &logger, manifestObj, nil)).To(Succeed())
+			Expect(manifestObj.Status.State).To(Equal(v1alpha1.ManifestStateReady))
+			Expect(manifestObj.ResourceVersion).To(Equal(resourceVersion))
+		})
+	})
+})
+
+var _ = Describe("Manifest chart preparation", func() {
+	logger := logr.Discard()
+	ctx := context.Background()
+	intervals := RequeueIntervals{Failure: 10 * time.Second, FailureMax: time.Minute}
+
+	expectPreparationError := func(r *ManifestReconciler, manifestObj *v1alpha1.Manifest) {
+		condition := expectState(ctx, r, manifestObj, v1alpha1.ManifestStateError, v1alpha1.ConditionStatusFalse)
+		Expect(condition.Message).To(ContainSubstring(labels.ComponentOwner))
+
+		// the error state is retried with a backoff instead of flipping back to processing
+		latest := &v1alpha1.Manifest{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(manifestObj), latest)).To(Succeed())
+		Expect(latest.GetAnnotations()).To(HaveKeyWithValue(labels.FailureCount, "1"))
+		Expect(remainingFailureBackoff(latest, intervals, time.Now())).To(BeNumerically(">", 0))
+
+		recorder, _ := r.Recorder.(*record.FakeRecorder)
+		Expect(recorder.Events).To(Receive(And(ContainSubstring(v1.EventTypeWarning),
+			ContainSubstring(ChartPreparationFailedReason), ContainSubstring(labels.ComponentOwner))))
+	}
+
+	When("the charts of a processing Manifest cannot be prepared", func() {
+		It("should move to the error state with the preparation error", func() {
+			// charts cannot be prepared without the owner label
+			manifestObj := manifestInState(v1alpha1.ManifestStateProcessing)
+			r := newFakeReconciler(ctx, manifestObj)
+			Expect(r.HandleProcessingState(ctx, &logger, manifestObj)).To(Succeed())
+			expectPreparationError(r, manifestObj)
+		})
+	})
+
+	When("the charts of a ready Manifest cannot be prepared", func() {
+		It("should move to the error state with the preparation error", func() {
+			manifestObj := manifestInState(v1alpha1.ManifestStateReady)
+			r := newFakeReconciler(ctx, manifestObj)
+			Expect(r.HandleReadyState(ctx, &logger, manifestObj)).To(Succeed())
+			expectPreparationError(r, manifestObj)
+		})
+	})
+})
+
+var _ = Describe("Manifest chart responses", func() {
+	logger := logr.Discard()
+	ctx := context.Background()
+
+	respond := func(r *ManifestReconciler, manifestObj *v1alpha1.Manifest, response *manifest.RequestError) {
+		responseChan := make(manifest.RequestErrChan, 1)
+		response.ResNamespacedName = client.ObjectKeyFromObject(manifestObj)
+		responseChan <- response
+		r.ResponseHandlerFunc(ctx, &logger, 1, responseChan, client.ObjectKeyFromObject(manifestObj))
+	}
+
+	When("a chart fails", func() {
+		It("should move to the error state with a recorded failure", func() {
+			manifestObj := manifestInState(v1alpha1.ManifestStateProcessing)
+			r := newFakeReconciler(ctx, manifestObj)
+			respond(r, manifestObj, &manifest.RequestError{ChartName: "nginx", Err: errors.New("install failed")})
+
+			condition := expectState(ctx, r, manifestObj, v1alpha1.ManifestStateError, v1alpha1.ConditionStatusFalse)
+			Expect(condition.Message).To(ContainSubstring("install failed"))
+			latest := &v1alpha1.Manifest{}
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(manifestObj), latest)).To(Succeed())
+			Expect(latest.GetAnnotations()).To(HaveKeyWithValue(labels.FailureCount, "1"))
+			_, chartConditionExists := getReadyConditionForComponent(latest, "nginx")
+			Expect(chartConditionExists).To(BeTrue())
+		})
+	})
+
+	When("a chart of a previously failed Manifest is ready", func() {
+		It("should move to the ready state and reset the failures", func() {
+			manifestObj := manifestInState(v1alpha1.ManifestStateProcessing)
+			recordFailure(manifestObj, time.Now())
+			r := newFakeReconciler(ctx, manifestObj)
+			respond(r, manifestObj, &manifest.RequestError{ChartName: "nginx", Ready: true})
+
+			expectState(ctx, r, manifestObj, v1alpha1.ManifestStateReady, v1alpha1.ConditionStatusTrue)
+			latest := &v1alpha1.Manifest{}
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(manifestObj), latest)).To(Succeed())
+			Expect(latest.GetAnnotations()).NotTo(HaveKey(labels.FailureCount))
+		})
+	})
+
+	When("resources of an installed chart are skipped in the ready check", func() {
+		It("should record an event listing the skipped resources", func() {
+			manifestObj := manifestInState(v1alpha1.ManifestStateProcessing)
+			r := newFakeReconciler(ctx, manifestObj)
+			respond(r, manifestObj, &manifest.RequestError{
+				ChartName: "nginx",
+				Ready:     true,
+				SkippedResources: kube.ResourceList{{
+					Name:      "job",
+					Namespace: metav1.NamespaceDefault,
+					Mapping:   &meta.RESTMapping{GroupVersionKind: v1.SchemeGroupVersion.WithKind("Pod")},
+				}},
+			})
+
+			expectState(ctx, r, manifestObj, v1alpha1.ManifestStateReady, v1alpha1.ConditionStatusTrue)
+			recorder, _ := r.Recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(ReadyCheckSkippedReason),
+				ContainSubstring("Pod default/job"))))
+		})
+	})
+
+	When("resources of an uninstalled chart are retained", func() {
+		It("should record an event listing the retained resources", func() {
+			manifestObj := manifestInState(v1alpha1.ManifestStateDeleting)
+			r := newFakeReconciler(ctx, manifestObj)
+			responseChan := make(manifest.RequestErrChan, 1)
+			responseChan <- &manifest.RequestError{
+				Ready:             true,
+				ChartName:         "nginx",
+				ResNamespacedName: client.ObjectKeyFromObject(manifestObj),
+				KeptResources: kube.ResourceList{{
+					Name:      "retained",
+					Namespace: metav1.NamespaceDefault,
+					Mapping:   &meta.RESTMapping{GroupVersionKind: v1.SchemeGroupVersion.WithKind("ConfigMap")},
+				}},
+			}
+			r.ResponseHandlerFunc(ctx, &logger, 1, responseChan, client.ObjectKeyFromObject(manifestObj))
+
+			recorder, _ := r.Recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(ResourcesRetainedReason),
+				ContainSubstring("ConfigMap default/retained"))))
+		})
+	})
+})
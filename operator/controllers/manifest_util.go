@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/kyma-project/manifest-operator/api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -32,7 +33,10 @@ func addReadyConditionForObjects(manifest *v1alpha1.Manifest, installItems []v1a
 			}
 			status.Conditions = append(status.Conditions, *condition)
 		}
-		condition.LastTransitionTime = &metav1.Time{Time: time.Now()}
+		// keep the transition time stable, so re-applying an unchanged condition leaves the status untouched
+		if !exists || condition.Status != conditionStatus || condition.LastTransitionTime == nil {
+			condition.LastTransitionTime = &metav1.Time{Time: time.Now()}
+		}
 		condition.Message = message
 		condition.Status = conditionStatus
 		if installItem.ClientConfig != "" || installItem.Overrides != "" {
@@ -48,3 +52,8 @@ func addReadyConditionForObjects(manifest *v1alpha1.Manifest, installItems []v1a
 		}
 	}
 }
+
+// manifestStatusChanged compares state, conditions and observed generation of both statuses.
+func manifestStatusChanged(previous, current *v1alpha1.ManifestStatus) bool {
+	return !equality.Semantic.DeepEqual(previous, current)
+}
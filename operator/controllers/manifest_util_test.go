@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"github.com/kyma-project/manifest-operator/api/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifest status conditions", func() {
+	installItems := []v1alpha1.InstallItem{{ChartName: "nginx"}}
+
+	When("a ready condition is re-applied without status change", func() {
+		It("should keep the transition time and leave the status unchanged", func() {
+			manifestObj := &v1alpha1.Manifest{}
+			addReadyConditionForObjects(manifestObj, installItems, v1alpha1.ConditionStatusTrue, "installation successful")
+			transitionTime := manifestObj.Status.Conditions[0].LastTransitionTime
+
+			previousStatus := manifestObj.Status.DeepCopy()
+			addReadyConditionForObjects(manifestObj, installItems, v1alpha1.ConditionStatusTrue, "installation successful")
+			Expect(manifestObj.Status.Conditions).To(HaveLen(1))
+			Expect(manifestObj.Status.Conditions[0].LastTransitionTime).To(Equal(transitionTime))
+			Expect(manifestStatusChanged(previousStatus, &manifestObj.Status)).To(BeFalse())
+		})
+	})
+
+	When("a ready condition changes its status", func() {
+		It("should report the status as changed", func() {
+			manifestObj := &v1alpha1.Manifest{}
+			addReadyConditionForObjects(manifestObj, installItems, v1alpha1.ConditionStatusUnknown, "installation processing")
+
+			previousStatus := manifestObj.Status.DeepCopy()
+			addReadyConditionForObjects(manifestObj, installItems, v1alpha1.ConditionStatusTrue, "installation successful")
+			Expect(manifestObj.Status.Conditions[0].Status).To(Equal(v1alpha1.ConditionStatusTrue))
+			Expect(manifestStatusChanged(previousStatus, &manifestObj.Status)).To(BeTrue())
+
+			previousStatus = manifestObj.Status.DeepCopy()
+			manifestObj.Generation = 2
+			manifestObj.SetObservedGeneration()
+			Expect(manifestStatusChanged(previousStatus, &manifestObj.Status)).To(BeTrue())
+		})
+	})
+})
@@ -3,6 +3,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/kyma-project/manifest-operator/operator/pkg/manifest"
@@ -16,16 +18,23 @@ type Workers interface {
 
 type ManifestWorkerPool struct {
 	Workers
-	logger      *logr.Logger
-	initialSize int
-	size        int
+	logger           *logr.Logger
+	initialSize      int
+	size             int
+	operationTimeout time.Duration
+	// inFlight holds the charts with a running operation, including cancelled ones that have not yet returned
+	inFlight      map[string]struct{}
+	inFlightMutex sync.Mutex
 }
 
-func NewManifestWorkers(logger *logr.Logger, workersConcurrentManifests int) *ManifestWorkerPool {
+func NewManifestWorkers(logger *logr.Logger, workersConcurrentManifests int, operationTimeout time.Duration,
+) *ManifestWorkerPool {
 	return &ManifestWorkerPool{
-		logger:      logger,
-		initialSize: workersConcurrentManifests,
-		size:        workersConcurrentManifests,
+		logger:           logger,
+		initialSize:      workersConcurrentManifests,
+		size:             workersConcurrentManifests,
+		operationTimeout: operationTimeout,
+		inFlight:         make(map[string]struct{}),
 	}
 }
 
@@ -37,7 +46,7 @@ func (mw *ManifestWorkerPool) StartWorkers(ctx context.Context, jobChan <-chan M
 				select {
 				case deployChart := <-deployJob:
 					mw.logger.Info(fmt.Sprintf("Processing chart with name %s by worker with id %d", deployChart.Info.ChartName, id))
-					deployChart.RequestErrChan <- handlerFn(deployChart.Info, deployChart.Mode, mw.logger)
+					deployChart.RequestErrChan <- mw.handleWithTimeout(deployChart, handlerFn)
 				case <-ctx.Done():
 					return
 				}
@@ -46,6 +55,74 @@ func (mw *ManifestWorkerPool) StartWorkers(ctx context.Context, jobChan <-chan M
 	}
 }
 
+// handleWithTimeout cancels the context passed to handlerFn after the operation timeout
+// and frees up the worker, even if handlerFn has not yet returned.
+// While an operation for the same chart of a Manifest is still running, the chart is reported as not ready,
+// so that no concurrent operation is started on the same release.
+func (mw *ManifestWorkerPool) handleWithTimeout(deployChart ManifestDeploy, handlerFn func(info manifest.DeployInfo, mode manifest.Mode, logger *logr.Logger) *manifest.RequestError,
+) *manifest.RequestError {
+	operationKey := fmt.Sprintf("%s/%s", deployChart.Info.ObjectKey.String(), deployChart.Info.ChartName)
+	if !mw.startOperation(operationKey) {
+		mw.logger.Info("previous operation still running, skipping chart", "chart", deployChart.Info.ChartName,
+			"resource", deployChart.Info.ObjectKey.String())
+		return &manifest.RequestError{
+			Ready:             false,
+			ResNamespacedName: deployChart.Info.ObjectKey,
+			ChartName:         deployChart.Info.ChartName,
+			ClientConfig:      deployChart.Info.ClientConfig,
+			Overrides:         deployChart.Info.Overrides,
+		}
+	}
+
+	if mw.operationTimeout <= 0 {
+		defer mw.finishOperation(operationKey)
+		return handlerFn(deployChart.Info, deployChart.Mode, mw.logger)
+	}
+
+	ctx, cancel := context.WithTimeout(deployChart.Info.Ctx, mw.operationTimeout)
+	defer cancel()
+	deployChart.Info.Ctx = ctx
+
+	// buffered, so that a late response does not block the handler go-routine
+	responseChan := make(chan *manifest.RequestError, 1)
+	go func() {
+		defer mw.finishOperation(operationKey)
+		responseChan <- handlerFn(deployChart.Info, deployChart.Mode, mw.logger)
+	}()
+
+	select {
+	case response := <-responseChan:
+		return response
+	case <-ctx.Done():
+		return &manifest.RequestError{
+			Ready:             false,
+			ResNamespacedName: deployChart.Info.ObjectKey,
+			Err: fmt.Errorf("operation for chart %s cancelled after %s: %w",
+				deployChart.Info.ChartName, mw.operationTimeout, ctx.Err()),
+			ChartName:    deployChart.Info.ChartName,
+			ClientConfig: deployChart.Info.ClientConfig,
+			Overrides:    deployChart.Info.Overrides,
+		}
+	}
+}
+
+// startOperation marks the operation as running and returns false, if it is already running.
+func (mw *ManifestWorkerPool) startOperation(operationKey string) bool {
+	mw.inFlightMutex.Lock()
+	defer mw.inFlightMutex.Unlock()
+	if _, running := mw.inFlight[operationKey]; running {
+		return false
+	}
+	mw.inFlight[operationKey] = struct{}{}
+	return true
+}
+
+func (mw *ManifestWorkerPool) finishOperation(operationKey string) {
+	mw.inFlightMutex.Lock()
+	defer mw.inFlightMutex.Unlock()
+	delete(mw.inFlight, operationKey)
+}
+
 func (mw *ManifestWorkerPool) GetWorkerPoolSize() int {
 	return mw.size
 }
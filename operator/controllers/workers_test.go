@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/manifest-operator/operator/pkg/manifest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifest worker operation timeout", func() {
+	logger := logr.Discard()
+	blockingHandler := func(info manifest.DeployInfo, _ manifest.Mode, _ *logr.Logger) *manifest.RequestError {
+		// simulates a hanging chart operation, which only returns on cancellation
+		<-info.Ctx.Done()
+		return &manifest.RequestError{Ready: true}
+	}
+	deployChart := ManifestDeploy{
+		Info: manifest.DeployInfo{Ctx: context.Background(), ChartInfo: &manifest.ChartInfo{ChartName: "hanging"}},
+	}
+
+	When("the operation exceeds the timeout", func() {
+		It("should cancel the operation and report an error", func() {
+			workers := NewManifestWorkers(&logger, 1, 10*time.Millisecond)
+			response := workers.handleWithTimeout(deployChart, blockingHandler)
+			Expect(response.Ready).To(BeFalse())
+			Expect(errors.Is(response.Err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(response.ChartName).To(Equal("hanging"))
+		})
+	})
+
+	When("the operation finishes within the timeout", func() {
+		It("should return the handler response", func() {
+			workers := NewManifestWorkers(&logger, 1, time.Minute)
+			response := workers.handleWithTimeout(deployChart,
+				func(info manifest.DeployInfo, _ manifest.Mode, _ *logr.Logger) *manifest.RequestError {
+					return &manifest.RequestError{Ready: true, ChartName: info.ChartName}
+				})
+			Expect(response.Ready).To(BeTrue())
+			Expect(response.Err).To(BeNil())
+		})
+	})
+
+	When("a cancelled operation has not yet returned", func() {
+		It("should report the chart as not ready without starting another operation", func() {
+			workers := NewManifestWorkers(&logger, 1, 10*time.Millisecond)
+			release := make(chan struct{})
+			var calls int32
+			// simulates a helm step, which does not observe the cancellation
+			uncancellableHandler := func(info manifest.DeployInfo, _ manifest.Mode, _ *logr.Logger) *manifest.RequestError {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return &manifest.RequestError{Ready: true, ChartName: info.ChartName}
+			}
+
+			response := workers.handleWithTimeout(deployChart, uncancellableHandler)
+			Expect(errors.Is(response.Err, context.DeadlineExceeded)).To(BeTrue())
+
+			response = workers.handleWithTimeout(deployChart, uncancellableHandler)
+			Expect(response.Ready).To(BeFalse())
+			Expect(response.Err).To(BeNil())
+			Expect(response.ChartName).To(Equal("hanging"))
+			Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+
+			close(release)
+			Eventually(func() bool {
+				return workers.handleWithTimeout(deployChart, uncancellableHandler).Ready
+			}).Should(BeTrue())
+		})
+	})
+})
@@ -23,6 +23,8 @@ import (
 
 	manifestv1alpha1 "github.com/kyma-project/manifest-operator/api/api/v1alpha1"
 	opLabels "github.com/kyma-project/manifest-operator/operator/pkg/labels"
+	"github.com/kyma-project/manifest-operator/operator/pkg/metrics"
+	"helm.sh/helm/v3/pkg/postrender"
 	v1 "k8s.io/api/core/v1"
 	apiExtensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -58,9 +60,10 @@ func init() {
 
 func main() {
 	var metricsAddr string
-	var enableLeaderElection, verifyInstallation, customStateCheck bool
-	var probeAddr string
-	var requeueSuccessInterval, requeueFailureInterval, requeueWaitingInterval time.Duration
+	var enableLeaderElection, verifyInstallation, customStateCheck, enableManifestMetrics bool
+	var probeAddr, postRendererPath string
+	var requeueSuccessInterval, requeueFailureInterval, requeueWaitingInterval, requeueWarningInterval time.Duration
+	var operationTimeout, requeueFailureMaxInterval time.Duration
 	var concurrentReconciles, workersConcurrentManifests int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":2020", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":2021", "The address the probe endpoint binds to.")
@@ -73,10 +76,20 @@ func main() {
 	flag.DurationVar(&requeueFailureInterval, "requeue-failure-interval", 10*time.Second,
 		"Determines the duration after which a failing reconciliation is retried and "+
 			"enqueued for a next try at recovering (e.g. because an Remote Synchronization Interaction failed).")
+	flag.DurationVar(&requeueFailureMaxInterval, "requeue-failure-max-interval", 10*time.Minute,
+		"Determines the maximum duration after which a failing reconciliation is retried, "+
+			"as the failure interval backs off exponentially with consecutive failures of the same Manifest.")
 	flag.DurationVar(&requeueWaitingInterval, "requeue-waiting-interval", 3*time.Second,
 		"Determines the duration after which a pending reconciliation is requeued, "+
 			"if the operator decides that it needs to wait for a certain state to update before it can proceed "+
 			"(e.g. because of pending finalizers in the deletion process).")
+	flag.DurationVar(&requeueWarningInterval, "requeue-warning-interval", 30*time.Second,
+		"Determines the duration after which a Manifest with degraded resources is enqueued for checking, "+
+			"if its resources recovered to a consistent state.")
+	flag.DurationVar(&operationTimeout, "operation-timeout", 0,
+		"Determines the duration after which a single chart installation or uninstallation is cancelled "+
+			"and reported as an error, freeing up the worker for other Manifests. "+
+			"Must exceed the helm Timeout of charts using Wait. Disabled by default.")
 	flag.IntVar(&concurrentReconciles, "concurrent-reconciles", 1,
 		"Determines the number of concurrent reconciliations by the operator.")
 	flag.IntVar(&workersConcurrentManifests, "workers-concurrent-manifest", 4,
@@ -86,6 +99,12 @@ func main() {
 			"before marking the resource state to a consistent state.")
 	flag.BoolVar(&customStateCheck, "custom-state-check", false,
 		"Indicates if desired state should be checked on custom resources")
+	flag.StringVar(&postRendererPath, "post-renderer", "",
+		"Path to an executable used as helm post renderer on all rendered charts, "+
+			"it receives the rendered manifest on stdin and returns the modified manifest on stdout.")
+	flag.BoolVar(&enableManifestMetrics, "enable-manifest-metrics", false,
+		"Indicates if reconciliation, render and sync metrics should be registered "+
+			"with the metrics endpoint of the operator.")
 
 	opts := zap.Options{
 		Development: true,
@@ -126,8 +145,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	if enableManifestMetrics {
+		metrics.Register()
+	}
+
 	workersLogger := ctrl.Log.WithName("workers")
-	manifestWorkers := controllers.NewManifestWorkers(&workersLogger, workersConcurrentManifests)
+	manifestWorkers := controllers.NewManifestWorkers(&workersLogger, workersConcurrentManifests, operationTimeout)
 	context := ctrl.SetupSignalHandler()
 
 	codec, err := manifestv1alpha1.NewCodec()
@@ -136,6 +159,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	var postRenderer postrender.PostRenderer
+	if postRendererPath != "" {
+		if postRenderer, err = postrender.NewExec(postRendererPath); err != nil {
+			setupLog.Error(err, "unable to initialize post renderer")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.ManifestReconciler{
 		Client:                  mgr.GetClient(),
 		Scheme:                  mgr.GetScheme(),
@@ -144,10 +175,14 @@ func main() {
 		VerifyInstallation:      verifyInstallation,
 		CustomStateCheck:        customStateCheck,
 		Codec:                   codec,
+		PostRenderer:            postRenderer,
+		Recorder:                mgr.GetEventRecorderFor(opLabels.ManifestOperator),
 		RequeueIntervals: controllers.RequeueIntervals{
-			Success: requeueSuccessInterval,
-			Failure: requeueFailureInterval,
-			Waiting: requeueWaitingInterval,
+			Success:    requeueSuccessInterval,
+			Failure:    requeueFailureInterval,
+			FailureMax: requeueFailureMaxInterval,
+			Waiting:    requeueWaitingInterval,
+			Warning:    requeueWarningInterval,
 		},
 	}).SetupWithManager(context, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Manifest")
@@ -1,12 +1,19 @@
 package labels
 
 const (
-	OperatorPrefix    = "operator.kyma-project.io"
-	ComponentPrefix   = "component.kyma-project.io"
-	Separator         = "/"
-	ComponentOwner    = OperatorPrefix + Separator + "kyma-name"
-	ManagedBy         = OperatorPrefix + Separator + "managed-by"
-	KymaOperator      = "kyma-operator"
-	ManifestOperator  = "manifest-operator"
-	ManifestFinalizer = "component.kyma-project.io/manifest"
+	OperatorPrefix          = "operator.kyma-project.io"
+	ComponentPrefix         = "component.kyma-project.io"
+	ModuleManagerPrefix     = "module-manager.kyma-project.io"
+	Separator               = "/"
+	ComponentOwner          = OperatorPrefix + Separator + "kyma-name"
+	ManagedBy               = OperatorPrefix + Separator + "managed-by"
+	KymaOperator            = "kyma-operator"
+	ManifestOperator        = "manifest-operator"
+	ManifestFinalizer       = "component.kyma-project.io/manifest"
+	KeepOnDelete            = ModuleManagerPrefix + Separator + "keep-on-delete"
+	ComponentKeepOnDelete   = ComponentPrefix + Separator + "keep-on-delete"
+	SkipReadyCheck          = ModuleManagerPrefix + Separator + "skip-ready-check"
+	ComponentSkipReadyCheck = ComponentPrefix + Separator + "skip-ready-check"
+	FailureCount            = ComponentPrefix + Separator + "failure-count"
+	LastFailedAttempt       = ComponentPrefix + Separator + "last-failed-attempt"
 )
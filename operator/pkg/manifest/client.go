@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 	"time"
 
 	manifestRest "github.com/kyma-project/manifest-operator/operator/pkg/rest"
@@ -12,12 +13,16 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/registry"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type OperationType string
@@ -27,17 +32,21 @@ type HelmOperation OperationType
 const (
 	OperationCreate HelmOperation = "create"
 	OperationDelete HelmOperation = "delete"
+	// OperationNone reads resources without creating or deleting their namespace.
+	OperationNone HelmOperation = ""
 )
 
+// crdEstablishTimeout matches the time helm install waits for CRDs to be established.
+const crdEstablishTimeout = 60 * time.Second
+
 type HelmClient struct {
-	kubeClient  *kube.Client
-	settings    *cli.EnvSettings
-	restGetter  *manifestRest.ManifestRESTClientGetter
-	clientSet   *kubernetes.Clientset
-	waitTimeout time.Duration
+	kubeClient *kube.Client
+	settings   *cli.EnvSettings
+	restGetter *manifestRest.ManifestRESTClientGetter
+	clientSet  kubernetes.Interface
 }
 
-func NewHelmClient(kubeClient *kube.Client, restGetter *manifestRest.ManifestRESTClientGetter, clientSet *kubernetes.Clientset, settings *cli.EnvSettings) *HelmClient {
+func NewHelmClient(kubeClient *kube.Client, restGetter *manifestRest.ManifestRESTClientGetter, clientSet kubernetes.Interface, settings *cli.EnvSettings) *HelmClient {
 	return &HelmClient{
 		kubeClient: kubeClient,
 		settings:   settings,
@@ -53,6 +62,13 @@ func (h *HelmClient) getGenericConfig(namespace string) (*action.Configuration,
 	}); err != nil {
 		return nil, err
 	}
+
+	// registry client for charts referenced by oci:// urls, credentials are read from the helm registry config
+	registryClient, err := registry.NewClient(registry.ClientOptCredentialsFile(h.settings.RegistryConfig))
+	if err != nil {
+		return nil, err
+	}
+	actionConfig.RegistryClient = registryClient
 	return actionConfig, nil
 }
 
@@ -106,18 +122,74 @@ func (h *HelmClient) SetFlags(args map[string]map[string]interface{}, actionClie
 		if !value.IsValid() || !value.CanSet() {
 			continue
 		}
-		switch value.Kind() {
-		case reflect.Bool:
-			value.SetBool(flagValue.(bool))
-		case reflect.Int64:
-			value.SetInt(flagValue.(int64))
-		case reflect.String:
-			value.SetString(flagValue.(string))
+		if err := setFlagValue(value, flagValue); err != nil {
+			return fmt.Errorf("invalid value %v for flag %s: %w", flagValue, flagKey, err)
 		}
 	}
 	return nil
 }
 
+// setFlagValue sets a parsed flag value on the action client field.
+// Flags are parsed with strvals, so a value can be a string even if the field is not.
+func setFlagValue(value reflect.Value, flagValue interface{}) error {
+	switch value.Kind() {
+	case reflect.Bool:
+		switch typedValue := flagValue.(type) {
+		case bool:
+			value.SetBool(typedValue)
+		case string:
+			parsed, err := strconv.ParseBool(typedValue)
+			if err != nil {
+				return err
+			}
+			value.SetBool(parsed)
+		default:
+			return fmt.Errorf("expected bool, got %T", flagValue)
+		}
+	case reflect.Int64:
+		if value.Type() == reflect.TypeOf(time.Duration(0)) {
+			duration, err := parseDuration(flagValue)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(duration))
+			return nil
+		}
+		switch typedValue := flagValue.(type) {
+		case int64:
+			value.SetInt(typedValue)
+		case string:
+			parsed, err := strconv.ParseInt(typedValue, 10, 64)
+			if err != nil {
+				return err
+			}
+			value.SetInt(parsed)
+		default:
+			return fmt.Errorf("expected integer, got %T", flagValue)
+		}
+	case reflect.String:
+		switch flagValue.(type) {
+		case string, int64, bool:
+			value.SetString(fmt.Sprint(flagValue))
+		default:
+			return fmt.Errorf("expected string, got %T", flagValue)
+		}
+	}
+	return nil
+}
+
+// parseDuration accepts duration strings like "5m", plain numbers are interpreted as seconds.
+func parseDuration(flagValue interface{}) (time.Duration, error) {
+	switch typedValue := flagValue.(type) {
+	case int64:
+		return time.Duration(typedValue) * time.Second, nil
+	case string:
+		return time.ParseDuration(typedValue)
+	default:
+		return 0, fmt.Errorf("expected duration, got %T", flagValue)
+	}
+}
+
 func (h *HelmClient) DownloadChart(actionClient *action.Install, chartName string) (string, error) {
 	return actionClient.ChartPathOptions.LocateChart(chartName, h.settings)
 }
@@ -129,30 +201,16 @@ func (h *HelmClient) HandleNamespace(actionClient *action.Install, operationType
 		if actionClient.Namespace == v1.NamespaceDefault {
 			return nil
 		}
-		ns := actionClient.Namespace
-		buf, err := util.GetNamespaceObjBytes(ns)
-		if err != nil {
-			return err
-		}
-		resourceList, err := h.kubeClient.Build(bytes.NewBuffer(buf), true)
-		if err != nil {
-			return err
-		}
 
-		switch operationType {
-		case OperationCreate:
-			if _, err = h.kubeClient.Create(resourceList); err != nil && !apierrors.IsAlreadyExists(err) {
+		// namespaces are deleted with DeleteNamespace, once the chart resources are gone
+		if operationType == OperationCreate {
+			resourceList, err := h.getNamespaceResources(actionClient.Namespace)
+			if err != nil {
 				return err
 			}
-		case OperationDelete:
-			if _, delErrors := h.kubeClient.Delete(resourceList); len(delErrors) > 0 {
-				var wrappedError error
-				for _, err = range delErrors {
-					wrappedError = fmt.Errorf("%w", err)
-				}
-				return wrappedError
+			if _, err = h.kubeClient.Create(resourceList); err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
 			}
-		default:
 		}
 	}
 	// set kubeclient namespace for override
@@ -160,6 +218,100 @@ func (h *HelmClient) HandleNamespace(actionClient *action.Install, operationType
 	return nil
 }
 
+// DeleteNamespace deletes the namespace of the action client, if it was created by manifest-operator
+// and no other resources remain in it. It returns true, if the namespace deletion was triggered.
+func (h *HelmClient) DeleteNamespace(ctx context.Context, actionClient *action.Install) (bool, error) {
+	if !actionClient.CreateNamespace || actionClient.Namespace == v1.NamespaceDefault {
+		return false, nil
+	}
+	resourceList, err := h.getNamespaceResources(actionClient.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	// only delete namespaces that were created by manifest-operator and still exist
+	managedResources, err := util.FilterManagedResources(resourceList)
+	if err != nil || len(managedResources) == 0 {
+		return false, err
+	}
+
+	// the namespace might be shared with other charts or resources not owned by manifest-operator
+	inUse, err := h.namespaceInUse(ctx, actionClient.Namespace)
+	if err != nil {
+		// a namespace which cannot be checked is kept, so that no foreign resources are deleted with it
+		log.FromContext(ctx).Info("namespace usage could not be checked, keeping namespace",
+			"namespace", actionClient.Namespace, "error", err.Error())
+		return false, nil
+	}
+	if inUse {
+		return false, nil
+	}
+
+	if _, delErrors := h.kubeClient.Delete(managedResources); len(delErrors) > 0 {
+		var wrappedError error
+		for _, err = range delErrors {
+			wrappedError = fmt.Errorf("%w", err)
+		}
+		return false, wrappedError
+	}
+	return true, nil
+}
+
+func (h *HelmClient) getNamespaceResources(namespace string) (kube.ResourceList, error) {
+	buf, err := util.GetNamespaceObjBytes(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return h.kubeClient.Build(bytes.NewBuffer(buf), true)
+}
+
+func (h *HelmClient) namespaceInUse(ctx context.Context, namespace string) (bool, error) {
+	apiResourceLists, err := h.clientSet.Discovery().ServerPreferredNamespacedResources()
+	if err != nil {
+		if discovery.IsGroupDiscoveryFailedError(err) {
+			// resources of unavailable API groups cannot be checked, keep the namespace
+			return true, nil
+		}
+		return false, err
+	}
+	restConfig, err := h.restGetter.ToRESTConfig()
+	if err != nil {
+		return false, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return false, err
+	}
+	return util.NamespaceInUse(ctx, dynamicClient, apiResourceLists, namespace)
+}
+
+// InstallCRDs creates the CustomResourceDefinitions of the manifest, which do not exist yet,
+// and waits for them to be established, so that their custom resources can be built from the same manifest.
+func (h *HelmClient) InstallCRDs(manifest string) error {
+	crdManifest, err := util.ExtractCRDManifest(manifest)
+	if err != nil || crdManifest == "" {
+		return err
+	}
+	crds, err := h.kubeClient.Build(bytes.NewBufferString(crdManifest), true)
+	if err != nil {
+		return err
+	}
+	existingCRDs, err := util.FilterExistingResources(crds)
+	if err != nil {
+		return err
+	}
+	// existing CRDs are updated together with the remaining resources
+	missingCRDs := crds.Difference(existingCRDs)
+	if len(missingCRDs) == 0 {
+		return nil
+	}
+	if _, err = h.kubeClient.Create(missingCRDs); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	// the rest mapper used to build resources is created with each build, so the new kinds are discovered
+	return h.kubeClient.Wait(missingCRDs, crdEstablishTimeout)
+}
+
 func (h *HelmClient) GetTargetResources(manifest string, targetNamespace string) (kube.ResourceList, error) {
 	resourceList, err := h.kubeClient.Build(bytes.NewBufferString(manifest), true)
 	if err != nil {
@@ -184,12 +336,12 @@ func (h *HelmClient) PerformCreate(targetResources kube.ResourceList) (*kube.Res
 func (h *HelmClient) CheckWaitForResources(targetResources kube.ResourceList, actionClient *action.Install, operation HelmOperation) error {
 	if actionClient.Wait && actionClient.Timeout != 0 {
 		if operation == OperationDelete {
-			return h.kubeClient.WaitForDelete(targetResources, h.waitTimeout)
+			return h.kubeClient.WaitForDelete(targetResources, actionClient.Timeout)
 		} else {
 			if actionClient.WaitForJobs {
-				return h.kubeClient.WaitWithJobs(targetResources, h.waitTimeout)
+				return h.kubeClient.WaitWithJobs(targetResources, actionClient.Timeout)
 			} else {
-				return h.kubeClient.Wait(targetResources, h.waitTimeout)
+				return h.kubeClient.Wait(targetResources, actionClient.Timeout)
 			}
 		}
 	}
@@ -0,0 +1,144 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
+	manifestRest "github.com/kyma-project/manifest-operator/operator/pkg/rest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	fakerest "k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+type expectedFlags struct {
+	wait      bool
+	timeout   time.Duration
+	namespace string
+}
+
+var _ = Describe("Action client flags", func() {
+	helmClient := &HelmClient{}
+	setFlags := func(flags map[string]interface{}) (*action.Install, error) {
+		actionClient := action.NewInstall(&action.Configuration{})
+		return actionClient, helmClient.SetFlags(map[string]map[string]interface{}{"flags": flags}, actionClient)
+	}
+
+	DescribeTable("setting parsed flag values",
+		func(flags map[string]interface{}, expected expectedFlags) {
+			actionClient, err := setFlags(flags)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actionClient.Wait).To(Equal(expected.wait))
+			Expect(actionClient.Timeout).To(Equal(expected.timeout))
+			Expect(actionClient.Namespace).To(Equal(expected.namespace))
+		},
+		Entry("duration string", map[string]interface{}{"Wait": true, "Timeout": "5m"},
+			expectedFlags{wait: true, timeout: 5 * time.Minute}),
+		Entry("duration in seconds", map[string]interface{}{"Timeout": int64(300)},
+			expectedFlags{timeout: 300 * time.Second}),
+		Entry("bool string", map[string]interface{}{"Wait": "true"}, expectedFlags{wait: true}),
+		Entry("numeric string field", map[string]interface{}{"Namespace": int64(1)}, expectedFlags{namespace: "1"}),
+		Entry("unknown flag", map[string]interface{}{"Unknown": "value"}, expectedFlags{}),
+	)
+
+	DescribeTable("rejecting invalid flag values",
+		func(flags map[string]interface{}) {
+			_, err := setFlags(flags)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("invalid duration", map[string]interface{}{"Timeout": "5 minutes"}),
+		Entry("invalid bool", map[string]interface{}{"Wait": "maybe"}),
+		Entry("mismatching type", map[string]interface{}{"Wait": map[string]interface{}{}}),
+	)
+
+	When("waiting with a duration string timeout", func() {
+		It("should wait for the resources within the timeout", func() {
+			actionClient, err := setFlags(map[string]interface{}{"Wait": true, "Timeout": "5m"})
+			Expect(err).NotTo(HaveOccurred())
+			waitingClient := &HelmClient{
+				kubeClient: kube.New(manifestRest.NewRESTClientGetter(&rest.Config{Host: "https://127.0.0.1:1"})),
+			}
+			Expect(waitingClient.CheckWaitForResources(kube.ResourceList{}, actionClient, OperationCreate)).
+				To(Succeed())
+		})
+	})
+})
+
+// failingClientset fails the discovery of namespaced resources with err.
+type failingClientset struct {
+	*fake.Clientset
+	err error
+}
+
+func (c *failingClientset) Discovery() discovery.DiscoveryInterface {
+	return &failingDiscovery{FakeDiscovery: c.Clientset.Discovery().(*fakediscovery.FakeDiscovery), err: c.err}
+}
+
+type failingDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	err error
+}
+
+func (d *failingDiscovery) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	return nil, d.err
+}
+
+// newNamespaceKubeClient serves the managed namespace and records all other requests.
+func newNamespaceKubeClient(namespace string, requests *[]string) (*kube.Client, *cmdtesting.TestFactory) {
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	factory := cmdtesting.NewTestFactory()
+	factory.UnstructuredClient = &fakerest.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fakerest.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			*requests = append(*requests, req.Method+" "+req.URL.Path)
+			managedNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: map[string]string{labels.ManagedBy: labels.ManifestOperator},
+			}}
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(
+				bytes.NewReader([]byte(runtime.EncodeOrDie(codec, managedNamespace))))}, nil
+		}),
+	}
+	return &kube.Client{Factory: factory, Log: func(string, ...interface{}) {}}, factory
+}
+
+var _ = Describe("Namespace deletion", func() {
+	When("the usage of a managed namespace cannot be checked", func() {
+		It("should keep the namespace without an error", func() {
+			var requests []string
+			kubeClient, factory := newNamespaceKubeClient("managed", &requests)
+			defer factory.Cleanup()
+			helmClient := &HelmClient{
+				kubeClient: kubeClient,
+				clientSet:  &failingClientset{Clientset: fake.NewSimpleClientset(), err: errors.New("forbidden")},
+			}
+			actionClient := action.NewInstall(&action.Configuration{})
+			actionClient.CreateNamespace = true
+			actionClient.Namespace = "managed"
+
+			deleted, err := helmClient.DeleteNamespace(context.Background(), actionClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeFalse())
+			Expect(requests).To(ConsistOf("GET /namespaces/managed"))
+		})
+	})
+})
@@ -3,15 +3,19 @@ package manifest
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/kyma-project/manifest-operator/operator/pkg/custom"
+	"github.com/kyma-project/manifest-operator/operator/pkg/metrics"
 	manifestRest "github.com/kyma-project/manifest-operator/operator/pkg/rest"
 	"github.com/kyma-project/manifest-operator/operator/pkg/util"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/registry"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -36,16 +40,28 @@ type ChartInfo struct {
 	Overrides    map[string]interface{}
 }
 
+// FromRegistry signifies if the chart is pulled from an OCI registry instead of a helm repository.
+func (c *ChartInfo) FromRegistry() bool {
+	return c.ChartPath == "" && registry.IsOCI(c.Url)
+}
+
 type DeployInfo struct {
 	Ctx            context.Context
 	ManifestLabels map[string]string
 	*ChartInfo
 	client.ObjectKey
-	RestConfig *rest.Config
-	CheckFn    custom.CheckFnType
-	ReadyCheck bool
+	RestConfig   *rest.Config
+	CheckFn      custom.CheckFnType
+	ReadyCheck   bool
+	PostRenderer postrender.PostRenderer
 }
 
+// ErrResourcesDegraded signifies that all resources of a chart are installed, but not all of them are ready.
+var ErrResourcesDegraded = errors.New("resources installed but not ready")
+
+// ErrResourcesDrifted signifies that live resources of a chart were changed out of band and have to be re-applied.
+var ErrResourcesDrifted = errors.New("resources drifted from chart")
+
 type RequestErrChan chan *RequestError
 
 type RequestError struct {
@@ -55,6 +71,10 @@ type RequestError struct {
 	Overrides         map[string]interface{}
 	ResNamespacedName client.ObjectKey
 	Err               error
+	// KeptResources are retained on the target cluster, although their chart was uninstalled
+	KeptResources kube.ResourceList
+	// SkippedResources are installed, but do not gate the readiness of their chart
+	SkippedResources kube.ResourceList
 }
 
 func (r *RequestError) Error() string {
@@ -96,13 +116,16 @@ func NewOperations(logger *logr.Logger, restConfig *rest.Config, releaseName str
 }
 
 func (o *Operations) getClusterResources(deployInfo DeployInfo, operation HelmOperation) (kube.ResourceList, kube.ResourceList, error) {
-	if deployInfo.ChartPath == "" {
-		if err := o.repoHandler.Add(deployInfo.RepoName, deployInfo.Url); err != nil {
+	if deployInfo.ChartPath == "" && !deployInfo.FromRegistry() {
+		if err := o.repoHandler.Add(deployInfo.Ctx, deployInfo.RepoName, deployInfo.Url); err != nil {
 			return nil, nil, err
 		}
 	}
 
-	manifest, err := o.getManifestForChartPath(deployInfo.ChartPath, deployInfo.ChartName, o.actionClient, o.args)
+	// post renderer runs inside the helm render pipeline, before resources are parsed from the manifest
+	o.actionClient.PostRenderer = deployInfo.PostRenderer
+
+	manifest, err := o.getManifestForChartPath(deployInfo.Ctx, deployInfo.ChartPath, deployInfo.ChartName, o.actionClient, o.args)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -111,6 +134,13 @@ func (o *Operations) getClusterResources(deployInfo DeployInfo, operation HelmOp
 		return nil, nil, err
 	}
 
+	if operation == OperationCreate {
+		// custom resources of the chart can only be built once their definitions exist
+		if err = o.helmClient.InstallCRDs(manifest); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	targetResources, err := o.helmClient.GetTargetResources(manifest, o.actionClient.Namespace)
 	if err != nil {
 		return nil, nil, err
@@ -125,99 +155,183 @@ func (o *Operations) getClusterResources(deployInfo DeployInfo, operation HelmOp
 }
 
 func (o *Operations) VerifyResources(deployInfo DeployInfo) (bool, error) {
-	targetResources, existingResources, err := o.getClusterResources(deployInfo, "")
+	targetResources, existingResources, err := o.getClusterResources(deployInfo, OperationNone)
 	if err != nil {
 		return false, errors.Wrap(err, "could not render current resources from manifest")
 	}
+
+	// missing resources are reported by verifyClusterResources, drift is only checked on existing ones
+	if len(targetResources) == len(existingResources) {
+		driftedResources, err := util.FilterDriftedResources(existingResources)
+		if err != nil {
+			return false, err
+		}
+		if len(driftedResources) > 0 {
+			return false, fmt.Errorf("%w: %s", ErrResourcesDrifted, util.DescribeResources(driftedResources))
+		}
+	}
+	return o.verifyClusterResources(deployInfo, targetResources, existingResources)
+}
+
+// verifyClusterResources checks that all target resources exist and, if enabled, that they are ready.
+// Existing resources failing the ready check are reported with ErrResourcesDegraded.
+func (o *Operations) verifyClusterResources(deployInfo DeployInfo, targetResources, existingResources kube.ResourceList,
+) (bool, error) {
 	if len(targetResources) > len(existingResources) {
 		return false, nil
 	}
+	if deployInfo.ReadyCheck {
+		// resources are present at this point, so a failing check is only reported as degradation
+		if ready, err := o.checkReadyState(deployInfo, targetResources); err != nil {
+			return false, err
+		} else if !ready {
+			return false, ErrResourcesDegraded
+		}
+	}
 	if deployInfo.CheckFn == nil {
 		return true, nil
 	}
 	return deployInfo.CheckFn(deployInfo.Ctx, deployInfo.ManifestLabels, deployInfo.ObjectKey, o.logger)
 }
 
-func (o *Operations) Install(deployInfo DeployInfo) (bool, error) {
-	targetResources, existingResources, err := o.getClusterResources(deployInfo, OperationCreate)
+// checkReadyState runs the helm ready check on all target resources, which are not opted out of it.
+func (o *Operations) checkReadyState(deployInfo DeployInfo, targetResources kube.ResourceList) (bool, error) {
+	checkedResources, skippedResources, err := util.FilterSkippedReadyCheckResources(targetResources)
 	if err != nil {
 		return false, err
 	}
+	for _, skipped := range skippedResources {
+		o.logger.Info("resource skipped in ready check", "kind", skipped.Mapping.GroupVersionKind.Kind,
+			"name", skipped.Name, "namespace", skipped.Namespace, "chart", deployInfo.ChartName)
+	}
+	return o.helmClient.CheckReadyState(deployInfo.Ctx, checkedResources)
+}
+
+// Install applies the chart resources to the target cluster and returns the resources skipped in the ready check.
+func (o *Operations) Install(deployInfo DeployInfo) (bool, kube.ResourceList, error) {
+	targetResources, existingResources, err := o.getClusterResources(deployInfo, OperationCreate)
+	if err != nil {
+		return false, nil, err
+	}
 
+	syncStart := time.Now()
 	if existingResources == nil && len(targetResources) > 0 {
 		if _, err = o.helmClient.PerformCreate(targetResources); err != nil {
-			return false, err
+			return false, nil, err
 		}
 	} else {
 		if _, err = o.helmClient.PerformUpdate(existingResources, targetResources, true); err != nil {
-			return false, err
+			return false, nil, err
 		}
 	}
+	metrics.RecordSyncDuration(string(OperationCreate), syncStart)
+	metrics.SetSyncedResources(deployInfo.ObjectKey.String(), deployInfo.ChartName, len(targetResources))
 
 	// if Wait or WaitForJobs is enabled, wait for resources to be ready with a timeout
-	if err = o.helmClient.CheckWaitForResources(targetResources, o.actionClient, OperationCreate); err != nil {
-		return false, err
+	waitResources, skippedResources, err := util.FilterSkippedReadyCheckResources(targetResources)
+	if err != nil {
+		return false, nil, err
+	}
+	if err = o.helmClient.CheckWaitForResources(waitResources, o.actionClient, OperationCreate); err != nil {
+		return false, nil, err
 	}
 
 	if deployInfo.ReadyCheck {
 		// check target resources are ready without waiting
-		if ready, err := o.helmClient.CheckReadyState(deployInfo.Ctx, targetResources); !ready || err != nil {
-			return ready, err
+		if ready, err := o.checkReadyState(deployInfo, targetResources); !ready || err != nil {
+			return ready, skippedResources, err
 		}
 	}
 
 	o.logger.Info("Install Complete!! Happy Manifesting!", "release", deployInfo.ReleaseName, "chart", deployInfo.ChartName)
 
 	// update manifest chart in a separate go-routine
-	if err = o.repoHandler.Update(); err != nil {
-		return false, err
+	if !deployInfo.FromRegistry() {
+		if err = o.repoHandler.Update(deployInfo.Ctx); err != nil {
+			return false, nil, err
+		}
 	}
 
 	// check custom function, if provided
 	if deployInfo.CheckFn != nil {
-		return deployInfo.CheckFn(deployInfo.Ctx, deployInfo.ManifestLabels, deployInfo.ObjectKey, o.logger)
+		ready, err := deployInfo.CheckFn(deployInfo.Ctx, deployInfo.ManifestLabels, deployInfo.ObjectKey, o.logger)
+		return ready, skippedResources, err
 	}
 
-	return true, nil
+	return true, skippedResources, nil
 }
 
-func (o *Operations) Uninstall(deployInfo DeployInfo) (bool, error) {
-	targetResources, existingResources, err := o.getClusterResources(deployInfo, OperationDelete)
+// Uninstall deletes the chart resources from the target cluster and returns the resources retained on deletion.
+func (o *Operations) Uninstall(deployInfo DeployInfo) (bool, kube.ResourceList, error) {
+	// namespace deletion is deferred until the chart resources are gone
+	targetResources, existingResources, err := o.getClusterResources(deployInfo, OperationNone)
 	if err != nil {
-		return false, err
+		return false, nil, err
+	}
+
+	// resources marked to be kept are neither deleted nor awaited for deletion
+	targetResources, _, err = util.FilterKeptResources(targetResources)
+	if err != nil {
+		return false, nil, err
+	}
+	existingResources, keptResources, err := util.FilterKeptResources(existingResources)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, kept := range keptResources {
+		o.logger.Info("resource retained on deletion", "kind", kept.Mapping.GroupVersionKind.Kind,
+			"name", kept.Name, "namespace", kept.Namespace, "chart", deployInfo.ChartName)
 	}
 
 	if existingResources != nil {
+		syncStart := time.Now()
 		response, delErrors := o.kubeClient.Delete(existingResources)
 		if len(delErrors) > 0 {
 			var wrappedError error
 			for _, err = range delErrors {
 				wrappedError = fmt.Errorf("%w", err)
 			}
-			return false, wrappedError
+			return false, nil, wrappedError
 		}
+		metrics.RecordSyncDuration(string(OperationDelete), syncStart)
 
 		o.logger.Info("component deletion executed", "resource count", len(response.Deleted))
 	}
+	metrics.ResetSyncedResources(deployInfo.ObjectKey.String(), deployInfo.ChartName)
 
 	if err = o.helmClient.CheckWaitForResources(targetResources, o.actionClient, OperationDelete); err != nil {
-		return false, err
+		return false, nil, err
+	}
+
+	// retained resources would be removed together with their namespace
+	if len(keptResources) == 0 {
+		deleted, err := o.helmClient.DeleteNamespace(deployInfo.Ctx, o.actionClient)
+		if err != nil {
+			return false, nil, err
+		}
+		if !deleted && o.actionClient.CreateNamespace && o.actionClient.Namespace != v1.NamespaceDefault {
+			o.logger.Info("namespace not deleted, it is not managed by manifest-operator or still in use",
+				"namespace", o.actionClient.Namespace, "chart", deployInfo.ChartName)
+		}
 	}
 
 	// update manifest chart in a separate go-routine
-	if err = o.repoHandler.Update(); err != nil {
-		return false, err
+	if !deployInfo.FromRegistry() {
+		if err = o.repoHandler.Update(deployInfo.Ctx); err != nil {
+			return false, nil, err
+		}
 	}
 
 	// check custom function, if provided
 	if deployInfo.CheckFn != nil {
-		return deployInfo.CheckFn(deployInfo.Ctx, deployInfo.ManifestLabels, deployInfo.ObjectKey, o.logger)
+		ready, err := deployInfo.CheckFn(deployInfo.Ctx, deployInfo.ManifestLabels, deployInfo.ObjectKey, o.logger)
+		return ready, keptResources, err
 	}
 
-	return true, nil
+	return true, keptResources, nil
 }
 
-func (o *Operations) getManifestForChartPath(chartPath, chartName string, actionClient *action.Install, args map[string]map[string]interface{}) (string, error) {
+func (o *Operations) getManifestForChartPath(ctx context.Context, chartPath, chartName string, actionClient *action.Install, args map[string]map[string]interface{}) (string, error) {
 	var err error
 	if chartPath == "" {
 		chartPath, err = o.helmClient.DownloadChart(actionClient, chartName)
@@ -227,7 +341,7 @@ func (o *Operations) getManifestForChartPath(chartPath, chartName string, action
 	}
 	o.logger.Info("chart located", "path", chartPath)
 
-	chartRequested, err := o.repoHandler.LoadChart(chartPath, actionClient)
+	chartRequested, err := o.repoHandler.LoadChart(ctx, chartPath, actionClient)
 	if err != nil {
 		return "", err
 	}
@@ -238,10 +352,12 @@ func (o *Operations) getManifestForChartPath(chartPath, chartName string, action
 	}
 
 	// retrieve manifest
-	release, err := actionClient.Run(chartRequested, mergedVals)
+	renderStart := time.Now()
+	release, err := actionClient.RunWithContext(ctx, chartRequested, mergedVals)
 	if err != nil {
 		return "", err
 	}
+	metrics.RecordRenderDuration(renderStart)
 	// TODO: Uncomment below to print manifest
 	// fmt.Println(release.Manifest)
 	return release.Manifest, nil
@@ -0,0 +1,171 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func newPod(name string, ready bool, annotations map[string]string) *v1.Pod {
+	readyStatus := v1.ConditionFalse
+	if ready {
+		readyStatus = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: v1.NamespaceDefault, Annotations: annotations},
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: readyStatus}},
+		},
+	}
+}
+
+func podInfo(pod *v1.Pod) *resource.Info {
+	return &resource.Info{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Object:    pod,
+		Mapping: &meta.RESTMapping{
+			GroupVersionKind: v1.SchemeGroupVersion.WithKind("Pod"),
+			Resource:         v1.SchemeGroupVersion.WithResource("pods"),
+			Scope:            meta.RESTScopeNamespace,
+		},
+	}
+}
+
+var _ = Describe("Verifying cluster resources", func() {
+	logger := logr.Discard()
+	deployInfo := DeployInfo{
+		Ctx:        context.Background(),
+		ChartInfo:  &ChartInfo{ChartName: "nginx"},
+		ReadyCheck: true,
+	}
+
+	newOperations := func(pods ...*v1.Pod) *Operations {
+		clientSet := fake.NewSimpleClientset()
+		for _, pod := range pods {
+			Expect(clientSet.Tracker().Add(pod)).To(Succeed())
+		}
+		return &Operations{logger: &logger, helmClient: &HelmClient{clientSet: clientSet}}
+	}
+
+	When("not all target resources exist", func() {
+		It("should report the resources as not ready", func() {
+			pod := newPod("nginx", true, nil)
+			ready, err := newOperations(pod).verifyClusterResources(deployInfo,
+				kube.ResourceList{podInfo(pod), podInfo(newPod("missing", true, nil))}, kube.ResourceList{podInfo(pod)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeFalse())
+		})
+	})
+
+	When("existing resources fail the ready check", func() {
+		It("should report the resources as degraded", func() {
+			pod := newPod("nginx", false, nil)
+			resources := kube.ResourceList{podInfo(pod)}
+			ready, err := newOperations(pod).verifyClusterResources(deployInfo, resources, resources)
+			Expect(errors.Is(err, ErrResourcesDegraded)).To(BeTrue())
+			Expect(ready).To(BeFalse())
+		})
+
+		It("should ignore resources skipped in the ready check", func() {
+			pod := newPod("nginx", false, map[string]string{labels.SkipReadyCheck: "true"})
+			resources := kube.ResourceList{podInfo(pod)}
+			ready, err := newOperations(pod).verifyClusterResources(deployInfo, resources, resources)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeTrue())
+		})
+
+		It("should ignore readiness if the ready check is disabled", func() {
+			pod := newPod("nginx", false, nil)
+			resources := kube.ResourceList{podInfo(pod)}
+			noReadyCheck := deployInfo
+			noReadyCheck.ReadyCheck = false
+			ready, err := newOperations(pod).verifyClusterResources(noReadyCheck, resources, resources)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeTrue())
+		})
+	})
+
+	When("existing resources are ready", func() {
+		It("should report the resources as ready", func() {
+			pod := newPod("nginx", true, nil)
+			resources := kube.ResourceList{podInfo(pod)}
+			ready, err := newOperations(pod).verifyClusterResources(deployInfo, resources, resources)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Chart sources", func() {
+	DescribeTable("pulling charts from OCI registries",
+		func(chartInfo ChartInfo, fromRegistry bool) {
+			Expect(chartInfo.FromRegistry()).To(Equal(fromRegistry))
+		},
+		Entry("oci url", ChartInfo{Url: "oci://registry.example.com/charts"}, true),
+		Entry("https url", ChartInfo{Url: "https://helm.nginx.com/stable", RepoName: "nginx-stable"}, false),
+		Entry("local chart path", ChartInfo{ChartPath: "/charts/nginx", Url: "oci://registry.example.com/charts"}, false),
+	)
+})
+
+// appendingPostRenderer adds a ConfigMap to the rendered manifest.
+type appendingPostRenderer struct{}
+
+func (appendingPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	postRendered := bytes.NewBufferString(renderedManifests.String())
+	postRendered.WriteString("\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: post-rendered\n")
+	return postRendered, nil
+}
+
+var _ = Describe("Post rendering charts", func() {
+	logger := logr.Discard()
+
+	It("should build the post-rendered manifest into the target resources", func() {
+		chartPath, err := ioutil.TempDir("", "chart")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(chartPath)
+		Expect(os.Mkdir(filepath.Join(chartPath, "templates"), 0o755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(chartPath, "Chart.yaml"),
+			[]byte("apiVersion: v2\nname: sample\nversion: 0.1.0\n"), 0o600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(chartPath, "templates", "configmap.yaml"),
+			[]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: rendered\n"), 0o600)).To(Succeed())
+
+		factory := cmdtesting.NewTestFactory()
+		defer factory.Cleanup()
+		helmClient := &HelmClient{kubeClient: &kube.Client{Factory: factory, Log: func(string, ...interface{}) {}}}
+		operations := &Operations{logger: &logger, helmClient: helmClient, repoHandler: NewRepoHandler(&logger, cli.New())}
+		actionClient := action.NewInstall(&action.Configuration{})
+		helmClient.SetDefaultClientConfig(actionClient, "sample")
+		actionClient.PostRenderer = appendingPostRenderer{}
+
+		manifest, err := operations.getManifestForChartPath(context.Background(), chartPath, "sample", actionClient,
+			map[string]map[string]interface{}{})
+		Expect(err).NotTo(HaveOccurred())
+		targetResources, err := helmClient.GetTargetResources(manifest, v1.NamespaceDefault)
+		Expect(err).NotTo(HaveOccurred())
+
+		names := make([]string, 0, len(targetResources))
+		for _, info := range targetResources {
+			names = append(names, info.Name)
+		}
+		Expect(names).To(ConsistOf("rendered", "post-rendered"))
+	})
+})
@@ -36,7 +36,7 @@ func NewRepoHandler(logger *logr.Logger, settings *cli.EnvSettings) *RepoHandler
 	}
 }
 
-func (r *RepoHandler) LoadChart(chartPath string, actionClient *action.Install) (*chart.Chart, error) {
+func (r *RepoHandler) LoadChart(ctx context.Context, chartPath string, actionClient *action.Install) (*chart.Chart, error) {
 	chartRequested, err := loader.Load(chartPath)
 	if err != nil {
 		return nil, err
@@ -57,7 +57,7 @@ func (r *RepoHandler) LoadChart(chartPath string, actionClient *action.Install)
 					ChartPath:        chartPath,
 					Keyring:          actionClient.ChartPathOptions.Keyring,
 					SkipUpdate:       false,
-					Getters:          getter.All(r.settings),
+					Getters:          r.getters(ctx),
 					RepositoryConfig: r.settings.RepositoryConfig,
 					RepositoryCache:  r.settings.RepositoryCache,
 				}
@@ -73,7 +73,7 @@ func (r *RepoHandler) LoadChart(chartPath string, actionClient *action.Install)
 	return chartRequested, nil
 }
 
-func (r *RepoHandler) Update() error {
+func (r *RepoHandler) Update(ctx context.Context) error {
 	repoFile := r.settings.RepositoryConfig
 
 	f, err := repo.LoadFile(repoFile)
@@ -82,7 +82,7 @@ func (r *RepoHandler) Update() error {
 	}
 	var repos []*repo.ChartRepository
 	for _, cfg := range f.Repositories {
-		chartRepo, err := repo.NewChartRepository(cfg, getter.All(r.settings))
+		chartRepo, err := repo.NewChartRepository(cfg, r.getters(ctx))
 		if err != nil {
 			return err
 		}
@@ -107,7 +107,7 @@ func (r *RepoHandler) Update() error {
 	return nil
 }
 
-func (r *RepoHandler) Add(repoName string, url string) error {
+func (r *RepoHandler) Add(ctx context.Context, repoName string, url string) error {
 	repoFile := r.settings.RepositoryConfig
 
 	// File locking mechanism
@@ -115,7 +115,7 @@ func (r *RepoHandler) Add(repoName string, url string) error {
 		return err
 	}
 	fileLock := flock.New(strings.Replace(repoFile, filepath.Ext(repoFile), ".lock", 1))
-	lockCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	lockCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
 	if err == nil && locked {
@@ -141,7 +141,7 @@ func (r *RepoHandler) Add(repoName string, url string) error {
 		URL:  url,
 	}
 
-	chartRepo, err := repo.NewChartRepository(&c, getter.All(r.settings))
+	chartRepo, err := repo.NewChartRepository(&c, r.getters(ctx))
 	if err != nil {
 		return fmt.Errorf("repository name (%s) already exists\n %w", repoName, err)
 	}
@@ -158,3 +158,24 @@ func (r *RepoHandler) Add(repoName string, url string) error {
 	fmt.Printf("%q has been added to your repositories\n", repoName)
 	return nil
 }
+
+// getters returns the helm getters, whose http requests time out at the deadline of ctx.
+// The helm repository and dependency downloads do not accept a context themselves.
+func (r *RepoHandler) getters(ctx context.Context) getter.Providers {
+	providers := getter.All(r.settings)
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return providers
+	}
+	timeout := time.Until(deadline)
+	for i := range providers {
+		if !providers[i].Provides("https") {
+			continue
+		}
+		newGetter := providers[i].New
+		providers[i].New = func(options ...getter.Option) (getter.Getter, error) {
+			return newGetter(append(options, getter.WithTimeout(timeout))...)
+		}
+	}
+	return providers
+}
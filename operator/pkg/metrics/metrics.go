@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "manifest_operator"
+
+	stateLabel     = "state"
+	operationLabel = "operation"
+	manifestLabel  = "manifest"
+	chartLabel     = "chart"
+)
+
+var (
+	reconcileStates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_total",
+		Help:      "Number of Manifest reconciliations partitioned by the state they ended in",
+	}, []string{stateLabel})
+
+	renderDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "render_duration_seconds",
+		Help:      "Duration of rendering a chart into its manifest",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of applying or deleting the resources of a chart on the target cluster",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{operationLabel})
+
+	syncedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "synced_resources",
+		Help:      "Number of resources synced to the target cluster for a chart of a Manifest",
+	}, []string{manifestLabel, chartLabel})
+)
+
+// Register adds all manifest-operator collectors to the controller-runtime registry.
+// It must only be called once, embedders exposing their own registry can skip it.
+func Register() {
+	metrics.Registry.MustRegister(reconcileStates, renderDuration, syncDuration, syncedResources)
+}
+
+func RecordReconcileState(state string) {
+	reconcileStates.WithLabelValues(state).Inc()
+}
+
+func RecordRenderDuration(start time.Time) {
+	renderDuration.Observe(time.Since(start).Seconds())
+}
+
+func RecordSyncDuration(operation string, start time.Time) {
+	syncDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+func SetSyncedResources(manifest, chart string, count int) {
+	syncedResources.WithLabelValues(manifest, chart).Set(float64(count))
+}
+
+func ResetSyncedResources(manifest, chart string) {
+	syncedResources.DeleteLabelValues(manifest, chart)
+}
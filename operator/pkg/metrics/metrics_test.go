@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("Manifest metrics", func() {
+	It("should record without registration", func() {
+		Expect(func() {
+			RecordReconcileState("Processing")
+			RecordRenderDuration(time.Now())
+			RecordSyncDuration("create", time.Now())
+			SetSyncedResources("default/manifest", "nginx", 3)
+			ResetSyncedResources("default/manifest", "nginx")
+		}).NotTo(Panic())
+	})
+
+	It("should expose recorded values once registered", func() {
+		Expect(Register).NotTo(Panic())
+
+		before := testutil.ToFloat64(reconcileStates.WithLabelValues("Ready"))
+		RecordReconcileState("Ready")
+		Expect(testutil.ToFloat64(reconcileStates.WithLabelValues("Ready"))).To(Equal(before + 1))
+
+		SetSyncedResources("default/manifest", "nginx", 3)
+		Expect(testutil.ToFloat64(syncedResources.WithLabelValues("default/manifest", "nginx"))).To(Equal(3.0))
+
+		families, err := metrics.Registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		names := make([]string, 0, len(families))
+		for _, family := range families {
+			names = append(names, family.GetName())
+		}
+		Expect(names).To(ContainElements("manifest_operator_reconcile_total", "manifest_operator_synced_resources"))
+
+		ResetSyncedResources("default/manifest", "nginx")
+		Expect(testutil.CollectAndCount(syncedResources)).To(BeZero())
+	})
+})
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+// WithContextCancellation returns a copy of config, whose requests are aborted once ctx is done.
+// This bounds clients which do not accept a context themselves, like the helm kube client.
+func WithContextCancellation(ctx context.Context, config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Wrap(func(delegate http.RoundTripper) http.RoundTripper {
+		return &contextRoundTripper{ctx: ctx, delegate: delegate}
+	})
+	return config
+}
+
+type contextRoundTripper struct {
+	ctx      context.Context
+	delegate http.RoundTripper
+}
+
+func (c *contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancel := context.WithCancel(req.Context())
+	done := make(chan struct{})
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+	}
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			cancel()
+		case <-done:
+		}
+	}()
+
+	resp, err := c.delegate.RoundTrip(req.WithContext(reqCtx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	// the request context has to stay alive until the response body is consumed
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var _ = Describe("Context cancellation of requests", func() {
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "https://cluster.local/api", nil)
+	}
+
+	When("the context is cancelled during a request", func() {
+		It("should abort the request", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			started := make(chan struct{})
+			// simulates a hanging api server, which only returns on cancellation of the request
+			hanging := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				close(started)
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			})
+			roundTripper := &contextRoundTripper{ctx: ctx, delegate: hanging}
+
+			go func() {
+				<-started
+				cancel()
+			}()
+			_, err := roundTripper.RoundTrip(newRequest())
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+	})
+
+	When("the context is already cancelled", func() {
+		It("should not send the request", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			sent := false
+			roundTripper := &contextRoundTripper{ctx: ctx, delegate: roundTripperFunc(
+				func(req *http.Request) (*http.Response, error) {
+					sent = true
+					return nil, nil
+				})}
+
+			_, err := roundTripper.RoundTrip(newRequest())
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			Expect(sent).To(BeFalse())
+		})
+	})
+
+	When("the request succeeds", func() {
+		It("should keep the request context alive until the body is closed", func() {
+			var reqCtx context.Context
+			roundTripper := &contextRoundTripper{ctx: context.Background(), delegate: roundTripperFunc(
+				func(req *http.Request) (*http.Response, error) {
+					reqCtx = req.Context()
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+				})}
+
+			resp, err := roundTripper.RoundTrip(newRequest())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reqCtx.Err()).To(BeNil())
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("ok"))
+
+			Expect(resp.Body.Close()).To(Succeed())
+			Expect(reqCtx.Err()).To(MatchError(context.Canceled))
+		})
+	})
+})
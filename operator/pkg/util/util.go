@@ -1,17 +1,30 @@
 package util
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"path"
+	"sort"
+	"strings"
 
+	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/releaseutil"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -27,7 +40,8 @@ func GetNamespaceObjBytes(clientNs string) ([]byte, error) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: clientNs,
 			Labels: map[string]string{
-				"name": clientNs,
+				"name":           clientNs,
+				labels.ManagedBy: labels.ManifestOperator,
 			},
 		},
 	}
@@ -60,6 +74,250 @@ func FilterExistingResources(resources kube.ResourceList) (kube.ResourceList, er
 	return requireUpdate, err
 }
 
+// FilterManagedResources returns the resources present on the cluster, which are labeled as managed by manifest-operator.
+func FilterManagedResources(resources kube.ResourceList) (kube.ResourceList, error) {
+	var managed kube.ResourceList
+
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		existing, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return errors.Wrapf(err, "could not get information about the resource %s / %s", info.Name, info.Namespace)
+		}
+
+		metaObject, err := meta.Accessor(existing)
+		if err != nil {
+			return err
+		}
+
+		if metaObject.GetLabels()[labels.ManagedBy] == labels.ManifestOperator {
+			managed.Append(info)
+		}
+		return nil
+	})
+
+	return managed, err
+}
+
+// FilterDriftedResources returns the existing resources whose live state differs from their target state.
+// A server-side dry-run apply shows each live object as it would be after applying its target,
+// so fields set by the target but changed out of band show up as a difference.
+// Fields the target does not set keep their live values, the status is not compared.
+func FilterDriftedResources(resources kube.ResourceList) (kube.ResourceList, error) {
+	var drifted kube.ResourceList
+	force := true
+
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target, err := json.Marshal(info.Object)
+		if err != nil {
+			return err
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		live, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			return errors.Wrapf(err, "could not get information about the resource %s / %s", info.Name, info.Namespace)
+		}
+		applied, err := helper.DryRun(true).WithFieldManager(labels.ManifestOperator).
+			Patch(info.Namespace, info.Name, types.ApplyPatchType, target, &metav1.PatchOptions{Force: &force})
+		if err != nil {
+			return errors.Wrapf(err, "could not dry-run apply the resource %s / %s", info.Name, info.Namespace)
+		}
+
+		liveContent, err := driftComparableContent(live)
+		if err != nil {
+			return err
+		}
+		appliedContent, err := driftComparableContent(applied)
+		if err != nil {
+			return err
+		}
+		if !equality.Semantic.DeepEqual(liveContent, appliedContent) {
+			drifted.Append(info)
+		}
+		return nil
+	})
+
+	return drifted, err
+}
+
+// driftComparableContent returns the object content without the fields changed by every apply.
+func driftComparableContent(obj runtime.Object) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	content = runtime.DeepCopyJSON(content)
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+	unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(content, "metadata", "generation")
+	unstructured.RemoveNestedField(content, "status")
+	return content, nil
+}
+
+// NamespaceInUse returns true, if the namespace still contains resources of the passed namespaced API resources,
+// which would be deleted together with the namespace. Resources in deletion, dependents of other resources
+// and resources the cluster creates in every namespace are not considered.
+func NamespaceInUse(ctx context.Context, dynamicClient dynamic.Interface, apiResourceLists []*metav1.APIResourceList,
+	namespace string,
+) (bool, error) {
+	for _, apiResourceList := range apiResourceLists {
+		groupVersion, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
+		if err != nil {
+			return false, err
+		}
+		for _, apiResource := range apiResourceList.APIResources {
+			if !apiResource.Namespaced || strings.Contains(apiResource.Name, "/") ||
+				!hasVerb(apiResource.Verbs, "list") || isClusterManagedResource(groupVersion.Group, apiResource.Name) {
+				continue
+			}
+			list, err := dynamicClient.Resource(groupVersion.WithResource(apiResource.Name)).
+				Namespace(namespace).List(ctx, metav1.ListOptions{})
+			if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+				continue
+			}
+			if err != nil {
+				return false, errors.Wrapf(err, "could not list %s in namespace %s", apiResource.Name, namespace)
+			}
+			for i := range list.Items {
+				if !isNamespaceDefaultResource(&list.Items[i]) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// isClusterManagedResource signifies API resources maintained by the cluster for other resources.
+func isClusterManagedResource(group, resource string) bool {
+	return resource == "events" && (group == "" || group == "events.k8s.io") ||
+		resource == "endpoints" && group == ""
+}
+
+// isNamespaceDefaultResource signifies resources, which do not keep a namespace in use.
+func isNamespaceDefaultResource(obj *unstructured.Unstructured) bool {
+	if obj.GetDeletionTimestamp() != nil || len(obj.GetOwnerReferences()) > 0 {
+		return true
+	}
+	switch obj.GetKind() {
+	case "ServiceAccount":
+		return obj.GetName() == "default"
+	case "ConfigMap":
+		return obj.GetName() == "kube-root-ca.crt"
+	case "Secret":
+		secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+		return secretType == string(v1.SecretTypeServiceAccountToken)
+	}
+	return false
+}
+
+func hasVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractCRDManifest returns the CustomResourceDefinitions of the manifest, in manifest order.
+// They have to exist before custom resources of the same manifest can be built.
+func ExtractCRDManifest(manifest string) (string, error) {
+	manifests := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(manifests))
+	for key := range manifests {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var crdManifest strings.Builder
+	for _, key := range keys {
+		var typeMeta metav1.TypeMeta
+		if err := yaml2.Unmarshal([]byte(manifests[key]), &typeMeta); err != nil {
+			return "", errors.Wrap(err, "could not read kind of manifest document")
+		}
+		if typeMeta.Kind == "CustomResourceDefinition" {
+			crdManifest.WriteString("---\n" + manifests[key] + "\n")
+		}
+	}
+	return crdManifest.String(), nil
+}
+
+// FilterKeptResources splits resources into the ones to be deleted and the ones to be retained on deletion,
+// either by the labels.KeepOnDelete or labels.ComponentKeepOnDelete annotation or by the helm resource policy "keep".
+func FilterKeptResources(resources kube.ResourceList) (kube.ResourceList, kube.ResourceList, error) {
+	return splitByAnnotations(resources, func(annotations map[string]string) bool {
+		return annotations[labels.KeepOnDelete] == "true" || annotations[labels.ComponentKeepOnDelete] == "true" ||
+			annotations[kube.ResourcePolicyAnno] == kube.KeepPolicy
+	})
+}
+
+// FilterSkippedReadyCheckResources splits resources into the ones gating readiness
+// and the ones opted out by the labels.SkipReadyCheck or labels.ComponentSkipReadyCheck annotation.
+func FilterSkippedReadyCheckResources(resources kube.ResourceList) (kube.ResourceList, kube.ResourceList, error) {
+	return splitByAnnotations(resources, func(annotations map[string]string) bool {
+		return annotations[labels.SkipReadyCheck] == "true" || annotations[labels.ComponentSkipReadyCheck] == "true"
+	})
+}
+
+// splitByAnnotations returns the resources not matching and matching the annotations predicate.
+func splitByAnnotations(resources kube.ResourceList, matches func(annotations map[string]string) bool,
+) (kube.ResourceList, kube.ResourceList, error) {
+	var remaining, matched kube.ResourceList
+
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		metaObject, err := meta.Accessor(info.Object)
+		if err != nil {
+			return err
+		}
+
+		if matches(metaObject.GetAnnotations()) {
+			matched.Append(info)
+			return nil
+		}
+
+		remaining.Append(info)
+		return nil
+	})
+
+	return remaining, matched, err
+}
+
+// DescribeResources lists the resources as "Kind namespace/name", e.g. for events and logs.
+func DescribeResources(resources kube.ResourceList) string {
+	descriptions := make([]string, 0, len(resources))
+	for _, info := range resources {
+		kind := ""
+		if info.Mapping != nil {
+			kind = info.Mapping.GroupVersionKind.Kind
+		} else if info.Object != nil {
+			kind = info.Object.GetObjectKind().GroupVersionKind().Kind
+		}
+		name := info.Name
+		if info.Namespace != "" {
+			name = info.Namespace + "/" + name
+		}
+		descriptions = append(descriptions, strings.TrimSpace(kind+" "+name))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
 func GetConfig(kubeConfig string, explicitPath string) (*rest.Config, error) {
 	if kubeConfig != "" {
 		// parameter string
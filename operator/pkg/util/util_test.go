@@ -0,0 +1,265 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/kyma-project/manifest-operator/operator/pkg/labels"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakerest "k8s.io/client-go/rest/fake"
+)
+
+func resourceWithAnnotations(name string, annotations map[string]string) *resource.Info {
+	return &resource.Info{
+		Name:      name,
+		Namespace: v1.NamespaceDefault,
+		Object: &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   v1.NamespaceDefault,
+			Annotations: annotations,
+		}},
+	}
+}
+
+func resourceNames(resources kube.ResourceList) []string {
+	names := make([]string, 0, len(resources))
+	for _, info := range resources {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+var _ = Describe("Resource annotation filters", func() {
+	DescribeTable("retaining resources on deletion",
+		func(annotations map[string]string, kept bool) {
+			deleted, retained, err := FilterKeptResources(kube.ResourceList{
+				resourceWithAnnotations("plain", nil),
+				resourceWithAnnotations("annotated", annotations),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			if kept {
+				Expect(resourceNames(deleted)).To(ConsistOf("plain"))
+				Expect(resourceNames(retained)).To(ConsistOf("annotated"))
+			} else {
+				Expect(resourceNames(deleted)).To(ConsistOf("plain", "annotated"))
+				Expect(retained).To(BeEmpty())
+			}
+		},
+		Entry("module-manager keep-on-delete", map[string]string{labels.KeepOnDelete: "true"}, true),
+		Entry("component keep-on-delete", map[string]string{labels.ComponentKeepOnDelete: "true"}, true),
+		Entry("helm resource policy keep", map[string]string{kube.ResourcePolicyAnno: kube.KeepPolicy}, true),
+		Entry("keep-on-delete not true", map[string]string{labels.KeepOnDelete: "false"}, false),
+		Entry("unrelated annotation", map[string]string{"foo": "bar"}, false),
+	)
+
+	DescribeTable("skipping resources in the ready check",
+		func(annotations map[string]string, skipped bool) {
+			checked, skippedResources, err := FilterSkippedReadyCheckResources(kube.ResourceList{
+				resourceWithAnnotations("plain", nil),
+				resourceWithAnnotations("annotated", annotations),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			if skipped {
+				Expect(resourceNames(checked)).To(ConsistOf("plain"))
+				Expect(resourceNames(skippedResources)).To(ConsistOf("annotated"))
+			} else {
+				Expect(resourceNames(checked)).To(ConsistOf("plain", "annotated"))
+				Expect(skippedResources).To(BeEmpty())
+			}
+		},
+		Entry("module-manager skip-ready-check", map[string]string{labels.SkipReadyCheck: "true"}, true),
+		Entry("component skip-ready-check", map[string]string{labels.ComponentSkipReadyCheck: "true"}, true),
+		Entry("skip-ready-check not true", map[string]string{labels.SkipReadyCheck: "false"}, false),
+		Entry("keep-on-delete only", map[string]string{labels.KeepOnDelete: "true"}, false),
+	)
+
+	When("resources are described", func() {
+		It("should list them by kind, namespace and name", func() {
+			Expect(DescribeResources(kube.ResourceList{
+				resourceWithAnnotations("first", nil),
+				{Name: "second", Mapping: &meta.RESTMapping{GroupVersionKind: v1.SchemeGroupVersion.WithKind("Namespace")}},
+			})).To(Equal("default/first, Namespace second"))
+		})
+	})
+
+	When("a resource has no accessible object metadata", func() {
+		It("should return an error", func() {
+			_, _, err := splitByAnnotations(kube.ResourceList{{Name: "invalid"}},
+				func(map[string]string) bool { return true })
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("no resources are passed", func() {
+		It("should return empty lists", func() {
+			remaining, matched, err := splitByAnnotations(nil, func(map[string]string) bool { return true })
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remaining).To(BeEmpty())
+			Expect(matched).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Namespace usage", func() {
+	const namespace = "managed"
+	apiResourceLists := []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: metav1.Verbs{"list"}},
+			{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: metav1.Verbs{"list"}},
+			{Name: "serviceaccounts", Namespaced: true, Kind: "ServiceAccount", Verbs: metav1.Verbs{"list"}},
+			{Name: "events", Namespaced: true, Kind: "Event", Verbs: metav1.Verbs{"list"}},
+			{Name: "namespaces", Namespaced: false, Kind: "Namespace", Verbs: metav1.Verbs{"list"}},
+		},
+	}}
+	objectMeta := func(name, namespace string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace}
+	}
+	deletionTimestamp := metav1.Now()
+	defaultResources := []runtime.Object{
+		&v1.ServiceAccount{ObjectMeta: objectMeta("default", namespace)},
+		&v1.ConfigMap{ObjectMeta: objectMeta("kube-root-ca.crt", namespace)},
+		&v1.Secret{ObjectMeta: objectMeta("default-token", namespace), Type: v1.SecretTypeServiceAccountToken},
+		&v1.Event{ObjectMeta: objectMeta("nginx.event", namespace)},
+		&v1.ConfigMap{ObjectMeta: objectMeta("foreign", v1.NamespaceDefault)},
+	}
+
+	DescribeTable("checking for remaining resources",
+		func(remaining []runtime.Object, inUse bool) {
+			dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, append(remaining, defaultResources...)...)
+			Expect(NamespaceInUse(context.Background(), dynamicClient, apiResourceLists, namespace)).To(Equal(inUse))
+		},
+		Entry("only resources created with every namespace", nil, false),
+		Entry("a foreign resource", []runtime.Object{&v1.ConfigMap{ObjectMeta: objectMeta("foreign", namespace)}},
+			true),
+		Entry("a foreign service account", []runtime.Object{
+			&v1.ServiceAccount{ObjectMeta: objectMeta("foreign", namespace)},
+		}, true),
+		Entry("a dependent of another resource", []runtime.Object{&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "dependent", Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner"}},
+		}}}, false),
+		Entry("a resource in deletion", []runtime.Object{&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "deleting", Namespace: namespace, DeletionTimestamp: &deletionTimestamp,
+			Finalizers: []string{"foregroundDeletion"},
+		}}}, false),
+	)
+})
+
+const (
+	crdDocument = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: samples.operator.kyma-project.io`
+	configMapDocument = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: sample`
+	customResourceDocument = `apiVersion: operator.kyma-project.io/v1alpha1
+kind: Sample
+metadata:
+  name: sample`
+)
+
+var _ = Describe("CRD extraction", func() {
+	DescribeTable("extracting CustomResourceDefinitions from a manifest",
+		func(manifest string, hasCRD bool) {
+			crdManifest, err := ExtractCRDManifest(manifest)
+			Expect(err).NotTo(HaveOccurred())
+			if !hasCRD {
+				Expect(crdManifest).To(BeEmpty())
+				return
+			}
+			Expect(crdManifest).To(ContainSubstring("kind: CustomResourceDefinition"))
+			Expect(crdManifest).NotTo(ContainSubstring("kind: ConfigMap"))
+			Expect(crdManifest).NotTo(ContainSubstring("kind: Sample"))
+		},
+		Entry("CRD with custom resource",
+			"---\n"+configMapDocument+"\n---\n"+crdDocument+"\n---\n"+customResourceDocument, true),
+		Entry("no CRD", "---\n"+configMapDocument+"\n---\n"+customResourceDocument, false),
+		Entry("empty manifest", "", false),
+	)
+
+	When("a manifest document is no valid yaml", func() {
+		It("should return an error", func() {
+			_, err := ExtractCRDManifest("---\nkind: [CustomResourceDefinition")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+// configMapInfo returns a ConfigMap target, served as live by a fake server which returns applied for dry-run applies.
+func configMapInfo(target, live, applied *v1.ConfigMap, requests *[]string) *resource.Info {
+	codec := scheme.Codecs.LegacyCodec(v1.SchemeGroupVersion)
+	respond := func(obj runtime.Object) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Content-Type", runtime.ContentTypeJSON)
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(
+			bytes.NewReader([]byte(runtime.EncodeOrDie(codec, obj))))}, nil
+	}
+	return &resource.Info{
+		Name:      target.Name,
+		Namespace: target.Namespace,
+		Object:    target,
+		Mapping: &meta.RESTMapping{
+			Resource:         v1.SchemeGroupVersion.WithResource("configmaps"),
+			GroupVersionKind: v1.SchemeGroupVersion.WithKind("ConfigMap"),
+			Scope:            meta.RESTScopeNamespace,
+		},
+		Client: &fakerest.RESTClient{
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+			Client: fakerest.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				*requests = append(*requests, req.Method+" "+req.URL.Query().Get("dryRun"))
+				if req.Method == http.MethodPatch {
+					return respond(applied)
+				}
+				return respond(live)
+			}),
+		},
+	}
+}
+
+var _ = Describe("Resource drift", func() {
+	configMap := func(data string, resourceVersion string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "sample", Namespace: v1.NamespaceDefault, ResourceVersion: resourceVersion,
+			},
+			Data: map[string]string{"key": data},
+		}
+	}
+
+	DescribeTable("comparing live resources with their dry-run applied targets",
+		func(live, applied *v1.ConfigMap, drifted bool) {
+			var requests []string
+			appliedWithManager := applied.DeepCopy()
+			appliedWithManager.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: labels.ManifestOperator}}
+
+			driftedResources, err := FilterDriftedResources(kube.ResourceList{
+				configMapInfo(configMap("target", ""), live, appliedWithManager, &requests),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(ConsistOf("GET ", http.MethodPatch+" "+metav1.DryRunAll))
+			if drifted {
+				Expect(resourceNames(driftedResources)).To(ConsistOf("sample"))
+			} else {
+				Expect(driftedResources).To(BeEmpty())
+			}
+		},
+		Entry("unchanged live resource", configMap("target", "1"), configMap("target", "2"), false),
+		Entry("live resource changed out of band", configMap("edited", "1"), configMap("target", "1"), true),
+	)
+})